@@ -1,7 +1,9 @@
 package acl
 
 var (
-	adminGroup = MakeGroup("admin")
+	// DefaultAuthorizer is used by User.Can, User.Decide and User.HasPermission. Members of the admin
+	// group bypass all checks. Set it's SuperGroups to nil to disable the bypass.
+	DefaultAuthorizer = NewAuthorizer("admin")
 )
 
 // RWX acl contants
@@ -0,0 +1,80 @@
+package acl
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/codeblanche/golibs/logr"
+)
+
+// Record describes a single access check for auditing. Permission is set for checks made with
+// HasPermission, Grant and Action for checks made against a grant.
+type Record struct {
+	Time       time.Time
+	Tenant     string
+	UserID     string
+	Groups     Groups
+	Action     rune
+	Permission string
+	Grant      Grant
+	Decision   Decision
+}
+
+// Auditor receives a Record for every access check made by an Authorizer
+type Auditor interface {
+	Audit(Record)
+}
+
+// AuditorFunc allows an ordinary function to be used as an Auditor
+type AuditorFunc func(Record)
+
+// Audit implements Auditor
+func (f AuditorFunc) Audit(r Record) {
+	f(r)
+}
+
+// LogAuditor is an Auditor writing denied checks as warnings and allowed checks as info messages
+// through logr
+var LogAuditor = NewLogAuditor(logr.Warnf, logr.Infof)
+
+// NewLogAuditor creates an Auditor writing denied checks through warnf and allowed checks through
+// infof, for example logr.Warnf and logr.Infof
+func NewLogAuditor(warnf, infof func(msg string, v ...interface{}) string) Auditor {
+	return AuditorFunc(func(r Record) {
+		check, outcome := "action "+string(r.Action), r.Decision.String()
+		if r.Permission != "" {
+			check = "permission " + r.Permission
+		}
+		if r.Permission != "" && !r.Decision.Superuser {
+			outcome = "denied"
+			if r.Decision.Allowed {
+				outcome = "allowed by role"
+			}
+		}
+		logf := warnf
+		if r.Decision.Allowed {
+			logf = infof
+		}
+		logf("acl: tenant %q user %q groups %v %s %s", r.Tenant, r.UserID, r.Groups, check, outcome)
+	})
+}
+
+// Sample wraps an Auditor so every denied check is recorded but allowed checks are only recorded at
+// the given rate between 0 (none) and 1 (all)
+func Sample(a Auditor, allowed float64) Auditor {
+	return AuditorFunc(func(r Record) {
+		if r.Decision.Allowed && (allowed <= 0 || rand.Float64() >= allowed) {
+			return
+		}
+		a.Audit(r)
+	})
+}
+
+// audit sends the record to the configured Auditor if any
+func (a *Authorizer) audit(r Record) {
+	if a.Auditor == nil {
+		return
+	}
+	r.Time = time.Now()
+	a.Auditor.Audit(r)
+}
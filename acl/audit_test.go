@@ -0,0 +1,70 @@
+package acl
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditor(t *testing.T) {
+	assert := assert.New(t)
+	records := []Record{}
+	a := NewAuthorizer("admin")
+	a.Auditor = AuditorFunc(func(r Record) { records = append(records, r) })
+	g := MakeGrant("u1", "", LevelFromString("r--------"))
+
+	a.Can(*NewUser("u1", []string{"g1"}), Read, g)
+	a.Can(*NewUser("u2", nil), Write, g)
+	a.HasPermission(*NewUser("u3", []string{"admin"}), "post:publish")
+
+	if assert.Len(records, 3) {
+		assert.Equal("u1", records[0].UserID)
+		assert.Equal(MakeGroups("g1"), records[0].Groups)
+		assert.Equal(Read, records[0].Action)
+		assert.True(records[0].Decision.Allowed)
+		assert.WithinDuration(time.Now(), records[0].Time, time.Second)
+		assert.False(records[1].Decision.Allowed)
+		assert.Equal("post:publish", records[2].Permission)
+		assert.True(records[2].Decision.Superuser)
+	}
+}
+
+func TestSample(t *testing.T) {
+	assert := assert.New(t)
+	count := 0
+	counter := AuditorFunc(func(r Record) { count++ })
+	allowed, denied := Record{Decision: Decision{Allowed: true}}, Record{}
+
+	none := Sample(counter, 0)
+	none.Audit(allowed)
+	none.Audit(denied)
+	assert.Equal(1, count)
+
+	all := Sample(counter, 1)
+	all.Audit(allowed)
+	all.Audit(denied)
+	assert.Equal(3, count)
+}
+
+func TestLogAuditor(t *testing.T) {
+	assert := assert.New(t)
+	var warnings, infos []string
+	logf := func(to *[]string) func(string, ...interface{}) string {
+		return func(msg string, v ...interface{}) string {
+			*to = append(*to, fmt.Sprintf(msg, v...))
+			return ""
+		}
+	}
+	a := NewAuthorizer()
+	a.Auditor = NewLogAuditor(logf(&warnings), logf(&infos))
+	a.Can(*NewUser("u2", nil), Write, MakeGrant("u1", "", LevelFromString("rw-------")))
+	a.HasPermission(*NewUser("u2", nil), "post:publish")
+	a.Can(*NewUser("u1", nil), Write, MakeGrant("u1", "", LevelFromString("rw-------")))
+	assert.Equal([]string{
+		`acl: tenant "" user "u2" groups [] action w denied as no grant matched`,
+		`acl: tenant "" user "u2" groups [] permission post:publish denied`,
+	}, warnings)
+	assert.Len(infos, 1)
+}
@@ -0,0 +1,142 @@
+package acl
+
+import "context"
+
+// Authorizer tests users against grants and permissions. Each Authorizer holds it's own set of
+// superuser groups and user IDs that bypass all checks, so services can have per-tenant admins or no
+// bypass at all. Nil Groups and Roles fall back to the package level Registry and Roles. When an
+// Auditor is set every check is recorded through it.
+//
+// Superuser groups and IDs without a tenant only match users without a tenant and bypass checks in
+// every tenant. Tenant qualified entries such as MakeTenantGroup("acme", "admin") match users of
+// that tenant and entries qualified with AnyTenant match users of any tenant. Both only bypass
+// checks of grants in the user's own tenant.
+//
+// Grants of another tenant than the user's are treated as absent unless CrossTenant allows them.
+type Authorizer struct {
+	SuperGroups Groups
+	SuperUsers  []string
+	Groups      *GroupRegistry
+	Roles       *RoleRegistry
+	Auditor     Auditor
+	CrossTenant func(userTenant, grantTenant string) bool
+}
+
+// NewAuthorizer creates a new Authorizer with the given superuser groups. Call with no groups for an
+// Authorizer without a bypass.
+func NewAuthorizer(superGroups ...string) *Authorizer {
+	return &Authorizer{
+		SuperGroups: MakeGroups(superGroups...),
+	}
+}
+
+// Can tests a user against an action and grant
+func (a *Authorizer) Can(u User, action rune, g Grant) bool {
+	return a.Decide(u, action, g).Allowed
+}
+
+// CanContext tests a user against an action and grant in the given context
+func (a *Authorizer) CanContext(ctx context.Context, u User, action rune, g Grant) bool {
+	return a.DecideContext(ctx, u, action, g).Allowed
+}
+
+// Decide tests a user against an action and grant and reports which grant decided the outcome.
+// The user's groups are expanded through the group registry so inherited groups also match.
+func (a *Authorizer) Decide(u User, action rune, g Grant) Decision {
+	return a.DecideContext(context.Background(), u, action, g)
+}
+
+// DecideContext is like Decide but evaluates grant conditions and time bounds against the given
+// context. See WithTime and WithAttributes.
+func (a *Authorizer) DecideContext(ctx context.Context, u User, action rune, g Grant) Decision {
+	u = a.resolve(u)
+	scope, super := a.superuser(u)
+	d := Decision{Allowed: true, Superuser: true}
+	if !super || scope != "" {
+		d = g.decide(a.check(ctx, u, action))
+	}
+	a.audit(Record{UserID: u.id, Tenant: u.tenant, Groups: u.groups, Action: action, Grant: g, Decision: d})
+	return d
+}
+
+// HasPermission tests a user against a named permission granted through roles. Roles assigned to
+// inherited groups also apply. Users with a tenant are looked up by their tenant qualified ID and
+// groups, for example acme:u1 and acme:billing.
+func (a *Authorizer) HasPermission(u User, permission string) bool {
+	u = a.resolve(u)
+	d := Decision{Allowed: true, Superuser: true}
+	if _, super := a.superuser(u); !super {
+		roles := a.Roles
+		if roles == nil {
+			roles = Roles
+		}
+		// names carrying another tenant never pick up that tenant's roles
+		id, groups := qualify(u.tenant, u.id), Groups{}
+		if Group(id).Tenant() != u.tenant {
+			id = ""
+		}
+		for _, g := range u.groups {
+			if g.Tenant() == u.tenant {
+				groups = append(groups, g)
+			}
+		}
+		d = Decision{Allowed: roles.Permissions(id, groups).Contains(Permission(permission))}
+	}
+	a.audit(Record{UserID: u.id, Tenant: u.tenant, Groups: u.groups, Permission: permission, Decision: d})
+	return d.Allowed
+}
+
+// IsSuperuser checks if the user bypasses checks made by this Authorizer. Users with a tenant
+// only bypass checks within their tenant.
+func (a *Authorizer) IsSuperuser(u User) bool {
+	_, super := a.superuser(a.resolve(u))
+	return super
+}
+
+// check creates the check of an already resolved user against an action
+func (a *Authorizer) check(ctx context.Context, u User, action rune) check {
+	c := check{ctx: ctx, user: u, action: action, crossTenant: a.CrossTenant}
+	if scope, super := a.superuser(u); super {
+		c.superTenant = scope
+	}
+	return c
+}
+
+// resolve qualifies the user's groups with it's tenant and expands inherited groups through the
+// configured or default group registry
+func (a *Authorizer) resolve(u User) User {
+	registry := a.Groups
+	if registry == nil {
+		registry = Registry
+	}
+	u.groups = registry.Expand(u.groups.qualify(u.tenant))
+	return u
+}
+
+// superuser checks an already resolved user against the superuser groups and IDs. It returns the
+// tenant the bypass is limited to, which is empty for users without a tenant. An entry only
+// matches users of it's own tenant, or of any tenant for AnyTenant entries, so names containing
+// TenantSeparator never let users without a tenant match a tenant's entries.
+func (a *Authorizer) superuser(u User) (string, bool) {
+	for _, id := range a.SuperUsers {
+		if id != "" && inScope(Group(id), u.tenant) && Group(id).Name() == u.id {
+			return u.tenant, true
+		}
+	}
+	for _, g := range u.groups {
+		if g.Tenant() != u.tenant {
+			continue
+		}
+		for _, s := range a.SuperGroups {
+			if inScope(s, u.tenant) && s.Name() == g.Name() {
+				return u.tenant, true
+			}
+		}
+	}
+	return "", false
+}
+
+// inScope checks if a superuser entry applies to users of the tenant
+func inScope(entry Group, tenant string) bool {
+	return entry.Tenant() == tenant || (tenant != "" && entry.Tenant() == AnyTenant)
+}
@@ -0,0 +1,46 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizerSuperusers(t *testing.T) {
+	assert := assert.New(t)
+	g := MakeGrant("owner", "", LevelFromString("rwx------"))
+	admin := *NewUser("u1", []string{"admin"})
+	tenantAdmin := *NewUser("u2", []string{"tenant-a-admin"})
+	root := *NewUser("root", nil)
+
+	none := NewAuthorizer()
+	assert.False(none.Can(admin, Read, g))
+	assert.False(none.IsSuperuser(admin))
+
+	tenant := NewAuthorizer("tenant-a-admin")
+	tenant.SuperUsers = []string{"root"}
+	assert.False(tenant.Can(admin, Read, g))
+	assert.True(tenant.Can(tenantAdmin, Read, g))
+	assert.True(tenant.Can(root, Write, g))
+	assert.True(tenant.Decide(root, Write, g).Superuser)
+	assert.True(tenant.HasPermission(root, "post:publish"))
+	assert.False(none.HasPermission(root, "post:publish"))
+
+	assert.True(admin.Can(Read, g))
+	assert.False(tenantAdmin.Can(Read, g))
+}
+
+func TestAuthorizerRegistries(t *testing.T) {
+	assert := assert.New(t)
+	a := NewAuthorizer("ops")
+	a.Groups = NewGroupRegistry()
+	a.Roles = NewRoleRegistry()
+	assert.NoError(a.Groups.Register("ops-oncall", "ops"))
+	a.Roles.Define("support", "ticket:close")
+	a.Roles.AssignUser("u1", "support")
+
+	assert.True(a.IsSuperuser(*NewUser("u2", []string{"ops-oncall"})))
+	assert.False(DefaultAuthorizer.IsSuperuser(*NewUser("u2", []string{"ops-oncall"})))
+	assert.True(a.HasPermission(*NewUser("u1", nil), "ticket:close"))
+	assert.False(NewUser("u1", nil).HasPermission("ticket:close"))
+}
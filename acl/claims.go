@@ -0,0 +1,84 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Default claim names used by UserFromClaims
+var (
+	ClaimTenant = "tenant"
+	ClaimUserID = "sub"
+	ClaimGroups = "groups"
+)
+
+// ClaimError is returned when a claim needed to build a User is missing, empty or of an unsupported
+// type. Value is nil for missing and empty claims.
+type ClaimError struct {
+	Claim string
+	Value interface{}
+}
+
+// Error implements error interface
+func (e ClaimError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("Claim %q is missing", e.Claim)
+	}
+	return fmt.Sprintf("Claim %q has unsupported type %T", e.Claim, e.Value)
+}
+
+// UserFromClaims creates a new User from a generic claims map such as a decoded JWT or session using
+// the ClaimUserID and ClaimGroups claim names. The optional ClaimTenant claim sets the user's tenant.
+func UserFromClaims(claims map[string]interface{}) (*User, error) {
+	u, err := UserFromClaimsKeys(claims, ClaimUserID, ClaimGroups)
+	if err != nil {
+		return nil, err
+	}
+	tenant, ok := claims[ClaimTenant].(string)
+	if !ok && claims[ClaimTenant] != nil {
+		return nil, ClaimError{Claim: ClaimTenant, Value: claims[ClaimTenant]}
+	}
+	u.tenant = tenant
+	return u, nil
+}
+
+// UserFromClaimsKeys creates a new User from a generic claims map using the given claim names.
+// The ID claim is required. The groups claim is optional and may be a list or a comma or space
+// separated string.
+func UserFromClaimsKeys(claims map[string]interface{}, idKey, groupsKey string) (*User, error) {
+	id, ok := claims[idKey].(string)
+	if !ok {
+		return nil, ClaimError{Claim: idKey, Value: claims[idKey]}
+	}
+	if id == "" {
+		return nil, ClaimError{Claim: idKey}
+	}
+	groups, ok := groupsFromClaim(claims[groupsKey])
+	if !ok {
+		return nil, ClaimError{Claim: groupsKey, Value: claims[groupsKey]}
+	}
+	return NewUser(id, groups), nil
+}
+
+// groupsFromClaim converts the supported group claim types into a list of group names
+func groupsFromClaim(v interface{}) ([]string, bool) {
+	switch t := v.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return strings.FieldsFunc(t, func(r rune) bool { return r == ',' || r == ' ' }), true
+	case []string:
+		return t, true
+	case []interface{}:
+		groups := []string{}
+		for _, g := range t {
+			s, ok := g.(string)
+			if !ok {
+				return nil, false
+			}
+			groups = append(groups, s)
+		}
+		return groups, true
+	}
+	return nil, false
+}
@@ -0,0 +1,78 @@
+package acl
+
+import (
+	"context"
+	"net"
+)
+
+// AttrRemoteIP is the attribute holding the client IP address tested by IPRange
+const AttrRemoteIP = "remote_ip"
+
+// Attributes of a request that grant conditions can be evaluated against
+type Attributes map[string]string
+
+// Condition restricts a grant to checks whose context satisfies it
+type Condition interface {
+	Allows(ctx context.Context) bool
+}
+
+// ConditionFunc allows an ordinary function to be used as a Condition
+type ConditionFunc func(ctx context.Context) bool
+
+// Allows implements Condition
+func (f ConditionFunc) Allows(ctx context.Context) bool {
+	return f(ctx)
+}
+
+// IPRange creates a Condition allowing requests whose AttrRemoteIP attribute is within one of the
+// given CIDR ranges
+func IPRange(cidrs ...string) (Condition, error) {
+	nets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return ConditionFunc(func(ctx context.Context) bool {
+		ip := net.ParseIP(AttributesFromContext(ctx)[AttrRemoteIP])
+		if ip == nil {
+			return false
+		}
+		for _, n := range nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// AttrIn creates a Condition allowing requests whose attribute matches one of the given values
+func AttrIn(name string, values ...string) Condition {
+	return ConditionFunc(func(ctx context.Context) bool {
+		v, ok := AttributesFromContext(ctx)[name]
+		if !ok {
+			return false
+		}
+		for _, one := range values {
+			if v == one {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// AllOf creates a Condition allowing requests satisfying every one of the given conditions
+func AllOf(conds ...Condition) Condition {
+	return ConditionFunc(func(ctx context.Context) bool {
+		for _, c := range conds {
+			if !c.Allows(ctx) {
+				return false
+			}
+		}
+		return true
+	})
+}
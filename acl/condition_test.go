@@ -0,0 +1,46 @@
+package acl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeBoundGrant(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	u := *NewUser("oncall", nil)
+	g := MakeGrant("oncall", "", LevelFromString("rw-------"))
+	g.NotBefore = now.Add(-time.Hour)
+	g.Expires = now.Add(time.Hour)
+
+	assert.True(u.Can(Write, g))
+	assert.False(u.CanContext(WithTime(context.Background(), now.Add(-2*time.Hour)), Write, g))
+	assert.False(u.CanContext(WithTime(context.Background(), now.Add(time.Hour)), Write, g))
+
+	// expired deny grants are treated as absent
+	deny := MakeDenyGrant("oncall", "", LevelFromString("rw-------"))
+	deny.Expires = now.Add(-time.Minute)
+	assert.True(u.Can(Write, GL{deny, g}))
+}
+
+func TestConditionalGrant(t *testing.T) {
+	assert := assert.New(t)
+	office, err := IPRange("10.0.0.0/8", "192.168.1.0/24")
+	assert.NoError(err)
+	_, err = IPRange("10.0.0.0")
+	assert.Error(err)
+
+	u := *NewUser("support", nil)
+	g := MakeGrant("support", "", LevelFromString("r--------"))
+	g.Condition = AllOf(office, AttrIn("ticket", "T-1", "T-2"))
+
+	ctx := WithAttributes(context.Background(), Attributes{AttrRemoteIP: "10.1.2.3"})
+	assert.False(u.CanContext(ctx, Read, g))
+	ctx = WithAttributes(ctx, Attributes{"ticket": "T-2"})
+	assert.True(u.CanContext(ctx, Read, g))
+	assert.False(u.CanContext(WithAttributes(ctx, Attributes{AttrRemoteIP: "8.8.8.8"}), Read, g))
+	assert.False(u.Can(Read, g))
+}
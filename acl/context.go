@@ -0,0 +1,58 @@
+package acl
+
+import (
+	"context"
+	"time"
+)
+
+// contextKey is unexported to prevent collisions with context keys defined in other packages
+type contextKey int
+
+const (
+	userKey contextKey = iota
+	timeKey
+	attributesKey
+)
+
+// NewContext returns a new Context carrying the given User
+func NewContext(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, userKey, u)
+}
+
+// FromContext returns the User stored in ctx, if any
+func FromContext(ctx context.Context) (*User, bool) {
+	u, ok := ctx.Value(userKey).(*User)
+	return u, ok && u != nil
+}
+
+// WithTime returns a new Context in which grants are checked at the given time instead of now
+func WithTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, timeKey, t)
+}
+
+// TimeFromContext returns the time set with WithTime or the current time
+func TimeFromContext(ctx context.Context) time.Time {
+	if t, ok := ctx.Value(timeKey).(time.Time); ok {
+		return t
+	}
+	return time.Now()
+}
+
+// WithAttributes returns a new Context carrying request attributes for grant conditions. Attributes
+// are added to any already carried by ctx.
+func WithAttributes(ctx context.Context, attrs Attributes) context.Context {
+	merged := Attributes{}
+	for k, v := range AttributesFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range attrs {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, attributesKey, merged)
+}
+
+// AttributesFromContext returns the request attributes carried by ctx, if any
+func AttributesFromContext(ctx context.Context) Attributes {
+	attrs, _ := ctx.Value(attributesKey).(Attributes)
+	return attrs
+}
@@ -0,0 +1,98 @@
+package acl
+
+import "fmt"
+
+// Effect of a grant, either allowing or denying the levels it carries
+type Effect int
+
+// Effect constant values. The zero value allows so existing grants keep their meaning.
+const (
+	Allow Effect = iota
+	Deny
+)
+
+// String implements Stringer
+func (e Effect) String() string {
+	if e == Deny {
+		return "deny"
+	}
+	return "allow"
+}
+
+// Class identifies which part of a grant (user, group or other bits) matched
+type Class int
+
+// Class constant values
+const (
+	ClassNone Class = iota
+	ClassUser
+	ClassGroup
+	ClassOther
+)
+
+// String implements Stringer
+func (c Class) String() string {
+	switch c {
+	case ClassUser:
+		return "user"
+	case ClassGroup:
+		return "group"
+	case ClassOther:
+		return "other"
+	}
+	return "none"
+}
+
+// Decision is the result of testing a user against a grant. It reports the grant and class that
+// decided the outcome so callers can explain why access was granted or refused.
+//
+// Grants are evaluated in the following order and the first match decides:
+//  1. deny matching the user's ID
+//  2. deny matching one of the user's groups
+//  3. allow matching the user's ID
+//  4. allow matching one of the user's groups
+//  5. deny in the other bits
+//  6. allow in the other bits
+//
+// When nothing matches access is denied.
+type Decision struct {
+	Allowed   bool
+	Superuser bool
+	Effect    Effect
+	Class     Class
+	Grant     *G
+}
+
+// String implements Stringer
+func (d Decision) String() string {
+	result := "denied"
+	if d.Allowed {
+		result = "allowed"
+	}
+	switch {
+	case d.Superuser:
+		return result + " by superuser bypass"
+	case d.Grant == nil:
+		return result + " as no grant matched"
+	}
+	return fmt.Sprintf("%s by %s %s grant (user: %q, group: %q, level: %s)",
+		result, d.Class, d.Effect, d.Grant.UserID, d.Grant.Group, d.Grant.Level)
+}
+
+// precedence ranks a matched class and effect following the order documented on Decision.
+// Lower values win.
+func precedence(c Class, e Effect) int {
+	switch c {
+	case ClassUser, ClassGroup:
+		if e == Deny {
+			return int(c) - 1
+		}
+		return int(c) + 1
+	case ClassOther:
+		if e == Deny {
+			return 4
+		}
+		return 5
+	}
+	return 6
+}
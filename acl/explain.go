@@ -0,0 +1,234 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Explanation is a structured trace of a single access check listing every G considered
+type Explanation struct {
+	Tenant    string
+	UserID    string
+	Groups    Groups
+	Action    rune
+	Superuser bool
+	Steps     []Step
+	Decision  Decision
+}
+
+// Step describes how a single G was considered. Skipped holds the reason the grant did not apply,
+// if any. The class results report if the user is a member of the class and if the class holds the
+// action.
+type Step struct {
+	Grant   G
+	Skipped string
+	User    ClassResult
+	Group   ClassResult
+	Other   ClassResult
+}
+
+// ClassResult is the outcome of testing one class of a G
+type ClassResult struct {
+	Member bool
+	Bit    bool
+}
+
+// Matched checks if the user is a member of the class and the class holds the action
+func (r ClassResult) Matched() bool {
+	return r.Member && r.Bit
+}
+
+// Access lists who may perform an action on a grant. Users and Groups are allowed explicitly. As
+// the groups of a user are not known from the grant, allowed users are only listed in Users when no
+// group is denied and in UsersUnlessDenied otherwise, as they are denied if a member of any of
+// DeniedGroups. Groups inheriting from a denied group are left out. Members of groups inheriting
+// from the listed groups are included implicitly.
+//
+// Everyone is set when the other bits of grants without a tenant allow the action, EveryoneIn lists
+// the tenants whose users are all allowed by the other bits of their tenant's grants. Superusers
+// and SuperGroups bypass the grant entirely.
+type Access struct {
+	Users             []string
+	UsersUnlessDenied []string
+	Groups            Groups
+	DeniedUsers       []string
+	DeniedGroups      Groups
+	Everyone          bool
+	EveryoneIn        []string
+	Superusers        []string
+	SuperGroups       Groups
+}
+
+// Explain traces the check of a user against an action and grant using the DefaultAuthorizer
+func Explain(u User, action rune, g Grant) Explanation {
+	return DefaultAuthorizer.Explain(u, action, g)
+}
+
+// WhoCan lists who may perform an action on a grant using the DefaultAuthorizer
+func WhoCan(action rune, g Grant) Access {
+	return DefaultAuthorizer.WhoCan(action, g)
+}
+
+// Explain traces the check of a user against an action and grant. The check is not audited.
+func (a *Authorizer) Explain(u User, action rune, g Grant) Explanation {
+	return a.ExplainContext(context.Background(), u, action, g)
+}
+
+// ExplainContext is like Explain but evaluates grant conditions and time bounds against the given
+// context
+func (a *Authorizer) ExplainContext(ctx context.Context, u User, action rune, g Grant) Explanation {
+	u = a.resolve(u)
+	c := a.check(ctx, u, action)
+	scope, super := a.superuser(u)
+	e := Explanation{
+		Tenant:    u.tenant,
+		UserID:    u.id,
+		Groups:    u.groups,
+		Action:    action,
+		Superuser: super,
+		Decision:  Decision{Allowed: true, Superuser: true},
+	}
+	if !super || scope != "" {
+		e.Decision = g.decide(c)
+	}
+	for _, one := range g.grants() {
+		p := PermSetFromLevel(one.Level).union(one.Perms.extended())
+		skipped := one.inactive(ctx)
+		if !c.inTenant(one.Tenant) {
+			skipped = "tenant " + one.Tenant + " does not apply"
+		}
+		e.Steps = append(e.Steps, Step{
+			Grant:   one,
+			Skipped: skipped,
+			User:    ClassResult{Member: one.isUser(u), Bit: p.Has(ClassUser, action)},
+			Group:   ClassResult{Member: one.Group != "" && u.groups.Contains(MakeTenantGroup(one.Tenant, string(one.Group))), Bit: p.Has(ClassGroup, action)},
+			Other:   ClassResult{Member: true, Bit: p.Has(ClassOther, action)},
+		})
+	}
+	return e
+}
+
+// String implements Stringer with one line per grant considered
+func (e Explanation) String() string {
+	lines := []string{fmt.Sprintf("tenant %q user %q groups %v action %c: %s", e.Tenant, e.UserID, e.Groups, e.Action, e.Decision)}
+	for i, s := range e.Steps {
+		line := fmt.Sprintf("  %d. %s grant tenant %q user %q group %q level %s perms %s: ", i+1, s.Grant.Effect, s.Grant.Tenant, s.Grant.UserID, s.Grant.Group, s.Grant.Level, s.Grant.Perms)
+		if s.Skipped != "" {
+			lines = append(lines, line+"skipped, "+s.Skipped)
+			continue
+		}
+		lines = append(lines, line+fmt.Sprintf("user %s, group %s, other %s", s.User, s.Group, s.Other))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String implements Stringer
+func (r ClassResult) String() string {
+	switch {
+	case r.Matched():
+		return "matched"
+	case !r.Member:
+		return "not a member"
+	}
+	return "no permission"
+}
+
+// WhoCan lists who may perform an action on a grant. Grants are considered as applying now with
+// conditions tested against an empty context.
+func (a *Authorizer) WhoCan(action rune, g Grant) Access {
+	return a.WhoCanContext(context.Background(), action, g)
+}
+
+// WhoCanContext is like WhoCan but evaluates grant conditions and time bounds against the given
+// context. User IDs and groups of grants with a tenant are tenant qualified.
+func (a *Authorizer) WhoCanContext(ctx context.Context, action rune, g Grant) Access {
+	access := Access{
+		Superusers:  append([]string{}, a.SuperUsers...),
+		SuperGroups: append(Groups{}, a.SuperGroups...),
+	}
+	everyone, otherDenied := map[string]bool{}, map[string]bool{}
+	for _, one := range g.grants() {
+		if one.inactive(ctx) != "" {
+			continue
+		}
+		p := PermSetFromLevel(one.Level).union(one.Perms.extended())
+		if one.UserID != "" && p.Has(ClassUser, action) {
+			id := qualify(one.Tenant, one.UserID)
+			if one.Effect == Deny {
+				access.DeniedUsers = appendUnique(access.DeniedUsers, id)
+			} else {
+				access.Users = appendUnique(access.Users, id)
+			}
+		}
+		if one.Group != "" && p.Has(ClassGroup, action) {
+			group := MakeTenantGroup(one.Tenant, string(one.Group))
+			if one.Effect == Deny && !access.DeniedGroups.Contains(group) {
+				access.DeniedGroups = append(access.DeniedGroups, group)
+			} else if one.Effect == Allow && !access.Groups.Contains(group) {
+				access.Groups = append(access.Groups, group)
+			}
+		}
+		if p.Has(ClassOther, action) {
+			everyone[one.Tenant] = everyone[one.Tenant] || one.Effect == Allow
+			otherDenied[one.Tenant] = otherDenied[one.Tenant] || one.Effect == Deny
+		}
+	}
+	access.Users = without(access.Users, access.DeniedUsers)
+	if len(access.DeniedGroups) > 0 {
+		access.Users, access.UsersUnlessDenied = []string{}, access.Users
+	}
+	registry := a.Groups
+	if registry == nil {
+		registry = Registry
+	}
+	groups := Groups{}
+	for _, group := range access.Groups {
+		denied := false
+		for _, ancestor := range registry.Expand(Groups{group}) {
+			denied = denied || access.DeniedGroups.Contains(ancestor)
+		}
+		if !denied {
+			groups = append(groups, group)
+		}
+	}
+	access.Groups = groups
+	for tenant, allowed := range everyone {
+		if !allowed || otherDenied[tenant] {
+			continue
+		}
+		if tenant == "" {
+			access.Everyone = true
+		} else {
+			access.EveryoneIn = append(access.EveryoneIn, tenant)
+		}
+	}
+	sort.Strings(access.EveryoneIn)
+	return access
+}
+
+// appendUnique appends the value unless already in the list
+func appendUnique(list []string, v string) []string {
+	for _, one := range list {
+		if one == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+// without returns the values of list not in exclude
+func without(list, exclude []string) []string {
+	result := []string{}
+	for _, v := range list {
+		found := false
+		for _, x := range exclude {
+			found = found || x == v
+		}
+		if !found {
+			result = append(result, v)
+		}
+	}
+	return result
+}
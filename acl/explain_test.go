@@ -0,0 +1,81 @@
+package acl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	assert := assert.New(t)
+	expired := MakeGrant("x", "", LevelFromString("rw-------"))
+	expired.Expires = time.Now().Add(-time.Hour)
+	gl := GL{
+		MakeGrant("", "editors", LevelFromString("---rw----")),
+		MakeDenyGrant("x", "", LevelFromString("-w-------")),
+		expired,
+	}
+	e := Explain(*NewUser("x", []string{"editors"}), Write, gl)
+	assert.False(e.Decision.Allowed)
+	assert.Equal(&gl[1], e.Decision.Grant)
+	assert.Equal(MakeGroups("editors"), e.Groups)
+	if assert.Len(e.Steps, 3) {
+		assert.Equal(ClassResult{Member: false, Bit: false}, e.Steps[0].User)
+		assert.True(e.Steps[0].Group.Matched())
+		assert.Equal(ClassResult{Member: true, Bit: false}, e.Steps[0].Other)
+		assert.True(e.Steps[1].User.Matched())
+		assert.Empty(e.Steps[1].Skipped)
+		assert.Contains(e.Steps[2].Skipped, "expired")
+	}
+	s := e.String()
+	assert.Contains(s, `user "x" groups [editors] action w: denied by user deny grant`)
+	assert.Contains(s, "1. allow grant")
+	assert.Contains(s, "user not a member, group matched, other no permission")
+	assert.Contains(s, "3. allow grant")
+
+	e = Explain(*NewUser("root", []string{"admin"}), Write, gl)
+	assert.True(e.Superuser)
+	assert.True(e.Decision.Allowed)
+}
+
+func TestWhoCan(t *testing.T) {
+	assert := assert.New(t)
+	tenant := MakeGrant("t1", "support", LevelFromString("rw-rw-r--"))
+	tenant.Tenant = "acme"
+	expired := MakeGrant("old", "", LevelFromString("rw-------"))
+	expired.Expires = time.Now().Add(-time.Hour)
+	gl := GL{
+		MakeGrant("u1", "editors", LevelFromString("rw-rw-r--")),
+		MakeGrant("u2", "contractors", LevelFromString("rw-rw----")),
+		MakeDenyGrant("u2", "contractors", LevelFromString("-w--w----")),
+		tenant,
+		expired,
+	}
+	access := WhoCan(Write, gl)
+	assert.Empty(access.Users, "Users may be members of the denied contractors group")
+	assert.Equal([]string{"u1", "acme:t1"}, access.UsersUnlessDenied)
+	assert.Equal(Groups{"editors", "acme:support"}, access.Groups)
+	assert.Equal([]string{"u2"}, access.DeniedUsers)
+	assert.Equal(Groups{"contractors"}, access.DeniedGroups)
+	assert.False(access.Everyone)
+	assert.Empty(access.EveryoneIn)
+	assert.Equal(MakeGroups("admin"), access.SuperGroups)
+
+	access = WhoCan(Write, gl[:2])
+	assert.Equal([]string{"u1", "u2"}, access.Users)
+	assert.Empty(access.UsersUnlessDenied)
+
+	access = WhoCan(Read, gl)
+	assert.True(access.Everyone)
+	assert.Equal([]string{"acme"}, access.EveryoneIn)
+	access = WhoCan(Read, GL{MakeDenyGrant("", "", LevelFromString("------r--")), gl[0], tenant})
+	assert.False(access.Everyone)
+	assert.Equal([]string{"acme"}, access.EveryoneIn)
+
+	a := NewAuthorizer()
+	a.Groups = NewGroupRegistry()
+	assert.NoError(a.Groups.Register("contractors-eu", "contractors"))
+	access = a.WhoCan(Write, GL{MakeGrant("", "contractors-eu", LevelFromString("---rw----")), gl[2]})
+	assert.Empty(access.Groups, "Groups inheriting from a denied group are denied")
+}
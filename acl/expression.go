@@ -0,0 +1,199 @@
+package acl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// classRunes maps the class runes of symbolic expressions to the classes they affect
+var classRunes = map[rune][]Class{
+	'u': {ClassUser},
+	'g': {ClassGroup},
+	'o': {ClassOther},
+	'a': {ClassUser, ClassGroup, ClassOther},
+}
+
+// ExpressionError is returned when a level expression cannot be parsed
+type ExpressionError struct {
+	Expression string
+	Pos        int
+	Msg        string
+}
+
+// Error implements error interface
+func (e ExpressionError) Error() string {
+	return fmt.Sprintf("Invalid level expression %q at position %d: %s", e.Expression, e.Pos, e.Msg)
+}
+
+// ParseLevel strictly parses a level from any of the following forms:
+//
+//	0754 or 754      octal
+//	rwxr-xr--        9 character string as produced by LevelToString
+//	u=rwx,g=rx,o=r   chmod style symbolic expression
+//
+// Symbolic expressions are applied to LevelNone. See Apply for the supported syntax.
+func ParseLevel(expr string) (Level, error) {
+	return Apply(LevelNone, expr)
+}
+
+// Apply applies a level expression to the base level, like chmod does to a file's mode. Octal and
+// 9 character forms replace the base level. Symbolic expressions modify it and consist of comma
+// separated clauses, each made of the classes u, g, o or a (all, the default when omitted) followed
+// by one or more operators + (add), - (remove) or = (set) and their rwx actions. For example g-w,
+// a+r or u=rw,go=r. The base level is returned unchanged with an error if any clause is invalid.
+func Apply(base Level, expr string) (Level, error) {
+	switch {
+	case expr == "":
+		return base, ExpressionError{Expression: expr, Msg: "empty expression"}
+	case isOctal(expr):
+		l, err := parseOctal(expr)
+		if err != nil {
+			return base, err
+		}
+		return l, nil
+	case isRWX(expr):
+		return LevelFromString(expr), nil
+	}
+	p, err := applySymbolic(PermSetFromLevel(base), expr, false)
+	if err != nil {
+		return base, err
+	}
+	return p.Level(), nil
+}
+
+// Apply applies an expression to the set. In addition to the forms supported by Apply for levels,
+// symbolic expressions may use the runes of registered actions and the set may be given in full as
+// produced by PermSet.String. Octal, 9 character and full forms replace the set. The set is returned
+// unchanged with an error if the expression is invalid.
+func (p PermSet) Apply(expr string) (PermSet, error) {
+	switch {
+	case expr == "":
+		return p, ExpressionError{Expression: expr, Msg: "empty expression"}
+	case isOctal(expr):
+		l, err := parseOctal(expr)
+		if err != nil {
+			return p, err
+		}
+		return PermSetFromLevel(l), nil
+	case isRWX(expr):
+		return PermSetFromLevel(LevelFromString(expr)), nil
+	}
+	if s, ok := parsePermString(expr); ok {
+		return s, nil
+	}
+	return applySymbolic(p, expr, true)
+}
+
+// applySymbolic applies comma separated symbolic clauses to the base set. Unless extended is set
+// only the rwx actions are accepted.
+func applySymbolic(base PermSet, expr string, extended bool) (PermSet, error) {
+	result, pos := base, 0
+	for _, clause := range strings.Split(expr, ",") {
+		p, err := applyClause(result, []rune(clause), expr, pos, extended)
+		if err != nil {
+			return base, err
+		}
+		result = p
+		pos += len([]rune(clause)) + 1
+	}
+	return result, nil
+}
+
+// applyClause applies a single symbolic clause starting at pos in expr to the base set
+func applyClause(base PermSet, clause []rune, expr string, pos int, extended bool) (PermSet, error) {
+	fail := func(i int, msg string, v ...interface{}) (PermSet, error) {
+		return base, ExpressionError{Expression: expr, Pos: pos + i, Msg: fmt.Sprintf(msg, v...)}
+	}
+	i := 0
+	who := []Class{}
+	for ; i < len(clause) && classRunes[clause[i]] != nil; i++ {
+		who = append(who, classRunes[clause[i]]...)
+	}
+	if len(who) == 0 {
+		who = classRunes['a']
+	}
+	if i == len(clause) {
+		return fail(i, "missing operator")
+	}
+	for i < len(clause) {
+		op := clause[i]
+		if op != '+' && op != '-' && op != '=' {
+			return fail(i, "unexpected %q, expected one of +-=", op)
+		}
+		i++
+		bits := uint32(0)
+		for ; i < len(clause); i++ {
+			bit, ok := actionBit(clause[i])
+			if !ok || (!extended && bit > 2) {
+				if !strings.ContainsRune("+-=", clause[i]) {
+					return fail(i, "unexpected %q, expected an action", clause[i])
+				}
+				break
+			}
+			bits |= 1 << bit
+		}
+		for _, c := range who {
+			switch op {
+			case '+':
+				base.set(c, base.class(c)|bits)
+			case '-':
+				base.set(c, base.class(c)&^bits)
+			case '=':
+				base.set(c, bits)
+			}
+		}
+	}
+	return base, nil
+}
+
+// isOctal checks if expr only contains octal digits
+func isOctal(expr string) bool {
+	return strings.Trim(expr, "01234567") == ""
+}
+
+// parseOctal converts an octal string of up to 3 significant digits to a level
+func parseOctal(expr string) (Level, error) {
+	l, err := strconv.ParseUint(expr, 8, 32)
+	if err != nil || l > 0777 {
+		return LevelNone, ExpressionError{Expression: expr, Msg: "octal level out of range 0-0777"}
+	}
+	return Level(l), nil
+}
+
+// isRWX checks if expr is a 9 character string in the form produced by LevelToString
+func isRWX(expr string) bool {
+	if len(expr) != 9 {
+		return false
+	}
+	for i := 0; i < 9; i++ {
+		if expr[i] != '-' && expr[i] != "rwx"[i%3] {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePermString parses a set in the full form produced by PermSet.String
+func parsePermString(expr string) (PermSet, bool) {
+	acts := Actions()
+	runes := []rune(expr)
+	p := PermSet{}
+	if len(runes) != 3*len(acts) {
+		return p, false
+	}
+	classes := classRunes['a']
+	for i, r := range runes {
+		if r == '-' {
+			continue
+		}
+		a := acts[i%len(acts)]
+		if r != a.Rune {
+			return p, false
+		}
+		bit, _ := actionBit(a.Rune)
+		c := classes[i/len(acts)]
+		p.set(c, p.class(c)|1<<bit)
+	}
+	return p, true
+}
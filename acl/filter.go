@@ -0,0 +1,181 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Granted is implemented by resources carrying a grant so lists of them can be filtered
+type Granted interface {
+	Grant() Grant
+}
+
+// Filter removes the items the user may not perform the action on from the list using the
+// DefaultAuthorizer. See Authorizer.Filter.
+func (u User) Filter(action rune, list interface{}) error {
+	return DefaultAuthorizer.Filter(u, action, list)
+}
+
+// Filter removes the items the user may not perform the action on from the list. List must be a
+// pointer to a slice whose elements implement Granted. The slice is filtered in place and keeps
+// the order of the permitted items.
+func (a *Authorizer) Filter(u User, action rune, list interface{}) error {
+	return a.FilterContext(context.Background(), u, action, list)
+}
+
+// FilterContext is like Filter but evaluates grant conditions and time bounds against the given context
+func (a *Authorizer) FilterContext(ctx context.Context, u User, action rune, list interface{}) error {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return errors.New("Parameter 'list' must be a pointer to a slice of acl.Granted elements")
+	}
+	s := v.Elem()
+	if !s.Type().Elem().Implements(reflect.TypeOf((*Granted)(nil)).Elem()) {
+		return errors.New("Parameter 'list' must be a pointer to a slice of acl.Granted elements")
+	}
+	n := 0
+	for i := 0; i < s.Len(); i++ {
+		item := s.Index(i)
+		if item.Kind() == reflect.Ptr && item.IsNil() {
+			continue
+		}
+		if !a.CanContext(ctx, u, action, item.Interface().(Granted).Grant()) {
+			continue
+		}
+		s.Index(n).Set(item)
+		n++
+	}
+	s.Set(s.Slice(0, n))
+	return nil
+}
+
+// Query returns a mongo query fragment matching documents whose G, stored in the given field, lets
+// the user perform the action. Grant fields are expected under their default bson names (userid,
+// tenant, group, level, perms, effect, notbefore and expires) with the level encoded by
+// Level.GetBSON. Grants outside their time bounds at the time of the call or of another tenant are
+// treated as absent. CrossTenant is not supported in queries.
+func (a *Authorizer) Query(u User, action rune, field string) bson.M {
+	u = a.resolve(u)
+	now := time.Now()
+	if scope, super := a.superuser(u); super && scope == "" {
+		return bson.M{}
+	} else if super {
+		return bson.M{"$and": append(matchTime(now, field+"."), matchTenant(scope, field+"."))}
+	}
+	if _, ok := actionName(action); !ok {
+		return matchNothing()
+	}
+	return bson.M{
+		field + ".effect": bson.M{"$ne": Deny},
+		"$and": append(matchTime(now, field+"."), matchTenant(u.tenant, field+"."), bson.M{"$or": []bson.M{
+			matchClass(u, ClassUser, action, field+"."),
+			matchClass(u, ClassGroup, action, field+"."),
+			matchClass(u, ClassOther, action, field+"."),
+		}}),
+	}
+}
+
+// QueryList returns a mongo query fragment matching documents whose GL, stored as an array in the
+// given field, lets the user perform the action. The evaluation order documented on Decision is
+// preserved.
+func (a *Authorizer) QueryList(u User, action rune, field string) bson.M {
+	u = a.resolve(u)
+	now := time.Now()
+	if scope, super := a.superuser(u); super && scope == "" {
+		return bson.M{}
+	} else if super {
+		return bson.M{field: bson.M{"$elemMatch": bson.M{"$and": append(matchTime(now, ""), matchTenant(scope, ""))}}}
+	}
+	if _, ok := actionName(action); !ok {
+		return matchNothing()
+	}
+	elem := func(e Effect, classes ...Class) bson.M {
+		or := []bson.M{}
+		for _, c := range classes {
+			or = append(or, matchClass(u, c, action, ""))
+		}
+		effect := bson.M{"$ne": Deny}
+		if e == Deny {
+			effect = bson.M{"$eq": Deny}
+		}
+		return bson.M{"$elemMatch": bson.M{"effect": effect, "$and": append(matchTime(now, ""), matchTenant(u.tenant, ""), bson.M{"$or": or})}}
+	}
+	return bson.M{"$and": []bson.M{
+		{field: bson.M{"$not": elem(Deny, ClassUser, ClassGroup)}},
+		{"$or": []bson.M{
+			{field: elem(Allow, ClassUser, ClassGroup)},
+			{"$and": []bson.M{
+				{field: bson.M{"$not": elem(Deny, ClassOther)}},
+				{field: elem(Allow, ClassOther)},
+			}},
+		}},
+	}}
+}
+
+// bsonField returns the field of G holding the permissions of the action
+func bsonField(action rune) string {
+	if LevelFromRune(action) != LevelNone {
+		return "level"
+	}
+	return "perms"
+}
+
+// matchClass builds the condition for a single class of a grant with the given field prefix
+func matchClass(u User, c Class, action rune, prefix string) bson.M {
+	name, _ := actionName(action)
+	can := prefix + bsonField(action) + "." + c.String() + "_can_" + name
+	switch c {
+	case ClassUser:
+		if u.id == "" {
+			return matchNothing()
+		}
+		return bson.M{prefix + "userid": bson.M{"$in": storedIDs(u)}, can: true}
+	case ClassGroup:
+		return bson.M{prefix + "group": bson.M{"$in": u.groups.unqualify(u.tenant)}, can: true}
+	}
+	return bson.M{can: true}
+}
+
+// storedIDs returns the user IDs a grant of the user's tenant may store for the user, it's ID with
+// and without the tenant, as G.isUser compares them qualified with the tenant
+func storedIDs(u User) []string {
+	id := qualify(u.tenant, u.id)
+	if u.tenant == "" || Group(id).Name() == id {
+		return []string{id}
+	}
+	return []string{Group(id).Name(), id}
+}
+
+// matchTime builds the conditions for a grant with the given field prefix to be within it's time
+// bounds. Zero or missing times leave the grant unbounded.
+func matchTime(now time.Time, prefix string) []bson.M {
+	return []bson.M{
+		{"$or": []bson.M{
+			{prefix + "notbefore": bson.M{"$exists": false}},
+			{prefix + "notbefore": time.Time{}},
+			{prefix + "notbefore": bson.M{"$lte": now}},
+		}},
+		{"$or": []bson.M{
+			{prefix + "expires": bson.M{"$exists": false}},
+			{prefix + "expires": time.Time{}},
+			{prefix + "expires": bson.M{"$gt": now}},
+		}},
+	}
+}
+
+// matchTenant builds the condition for a grant with the given field prefix to be in the tenant
+func matchTenant(tenant, prefix string) bson.M {
+	if tenant == "" {
+		return bson.M{prefix + "tenant": bson.M{"$in": []interface{}{"", nil}}}
+	}
+	return bson.M{prefix + "tenant": tenant}
+}
+
+// matchNothing returns a condition no document satisfies
+func matchNothing() bson.M {
+	return bson.M{"_id": bson.M{"$in": []interface{}{}}}
+}
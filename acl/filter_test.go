@@ -0,0 +1,87 @@
+package acl
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type doc struct {
+	Name string
+	G    G
+}
+
+func (d doc) Grant() Grant {
+	return d.G
+}
+
+func TestFilter(t *testing.T) {
+	assert := assert.New(t)
+	docs := []doc{
+		{"mine", MakeGrant("u1", "", LevelFromString("r--------"))},
+		{"team", MakeGrant("", "g1", LevelFromString("---r-----"))},
+		{"private", MakeGrant("u2", "", LevelFromString("rw-------"))},
+		{"public", MakeGrant("", "", LevelFromString("------r--"))},
+	}
+	assert.NoError(NewUser("u1", []string{"g1"}).Filter(Read, &docs))
+	names := []string{}
+	for _, d := range docs {
+		names = append(names, d.Name)
+	}
+	assert.Equal([]string{"mine", "team", "public"}, names)
+
+	ptrs := []*doc{{"mine", MakeGrant("u1", "", LevelFromString("r--------"))}, nil}
+	assert.NoError(NewUser("u2", nil).Filter(Read, &ptrs))
+	assert.Empty(ptrs)
+
+	assert.Error(NewUser("u1", nil).Filter(Read, docs))
+	assert.Error(NewUser("u1", nil).Filter(Read, &[]string{}))
+}
+
+func TestQuery(t *testing.T) {
+	assert := assert.New(t)
+	u := *NewUser("u1", []string{"g1"})
+	expected := []bson.M{
+		{"acl.userid": bson.M{"$in": []string{"u1"}}, "acl.level.user_can_write": true},
+		{"acl.group": bson.M{"$in": MakeGroups("g1")}, "acl.level.group_can_write": true},
+		{"acl.level.other_can_write": true},
+	}
+	q := DefaultAuthorizer.Query(u, Write, "acl")
+	assert.Equal(bson.M{"$ne": Deny}, q["acl.effect"])
+	and := q["$and"].([]bson.M)
+	if assert.Len(and, 4) {
+		assert.Contains(and[0]["$or"], bson.M{"acl.notbefore": time.Time{}})
+		assert.Contains(and[1]["$or"], bson.M{"acl.expires": time.Time{}})
+		assert.Equal(bson.M{"acl.tenant": bson.M{"$in": []interface{}{"", nil}}}, and[2])
+		assert.Equal(expected, and[3]["$or"])
+	}
+	assert.Equal(bson.M{}, DefaultAuthorizer.Query(*NewUser("u1", []string{"admin"}), Write, "acl"))
+
+	// grants of a tenant may store the user ID with or without the tenant
+	ids := bson.M{"$in": []string{"u1", "acme:u1"}}
+	for _, id := range []string{"u1", "acme:u1"} {
+		g := MakeGrant(id, "", LevelFromString("-w-------"))
+		g.Tenant = "acme"
+		for _, uid := range []string{"u1", "acme:u1"} {
+			tu := *NewTenantUser("acme", uid, nil)
+			assert.True(tu.Can(Write, g), uid+" "+id)
+			and := DefaultAuthorizer.Query(tu, Write, "acl")["$and"].([]bson.M)
+			assert.Equal(ids, and[3]["$or"].([]bson.M)[0]["acl.userid"], uid)
+		}
+	}
+	assert.Equal(matchNothing(), DefaultAuthorizer.Query(u, 'z', "acl"))
+}
+
+func TestQueryList(t *testing.T) {
+	assert := assert.New(t)
+	q := NewAuthorizer().QueryList(*NewUser("u1", []string{"g1"}), Read, "acl")
+	and := q["$and"].([]bson.M)
+	assert.Len(and, 2)
+	deny := and[0]["acl"].(bson.M)["$not"].(bson.M)["$elemMatch"].(bson.M)
+	assert.Equal(bson.M{"$eq": Deny}, deny["effect"])
+	assert.Len(deny["$and"], 4)
+	assert.Len(deny["$and"].([]bson.M)[3]["$or"], 2)
+}
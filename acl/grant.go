@@ -1,10 +1,37 @@
 package acl
 
+import (
+	"context"
+	"time"
+)
+
 // Grant interface defines the methods needed for acl package to validate a user against
 // the levels defined in the implementing structs.
 type Grant interface {
 	// CanUser tests the given user against the grant for the given level
 	canUser(User, Level) bool
+	// decide tests the user of the check against the grant and reports the deciding match
+	decide(check) Decision
+	// grants lists the G considered by the grant
+	grants() GL
+}
+
+// check holds a single test of a user against an action. The user's groups are already qualified
+// with it's tenant and expanded.
+type check struct {
+	ctx         context.Context
+	user        User
+	action      rune
+	superTenant string
+	crossTenant func(userTenant, grantTenant string) bool
+}
+
+// inTenant checks if grants of the given tenant apply to the user
+func (c check) inTenant(tenant string) bool {
+	if tenant == c.user.tenant {
+		return true
+	}
+	return c.crossTenant != nil && c.crossTenant(c.user.tenant, tenant)
 }
 
 // MakeGrant creates a new grant model
@@ -16,24 +43,91 @@ func MakeGrant(uid, group string, level Level) G {
 	}
 }
 
-// G type defines the model for access grant
+// MakeDenyGrant creates a new grant model that denies the given level
+func MakeDenyGrant(uid, group string, level Level) G {
+	g := MakeGrant(uid, group, level)
+	g.Effect = Deny
+	return g
+}
+
+// G type defines the model for access grant. A G with a Deny effect refuses the levels it carries
+// instead of granting them. See Decision for the order in which allow and deny grants are evaluated.
+//
+// A G only applies from NotBefore until Expires and while it's Condition allows the context of the
+// check. Zero times leave the grant unbounded and a nil Condition always allows. Grants that do not
+// apply are treated as absent. Conditions are not serialised.
+//
+// A G with a Tenant only applies to users of that tenant and it's Group is a group of that tenant.
+//
+// Level holds the rwx permissions. Perms holds the permissions of actions added with RegisterAction,
+// any rwx bits in Perms are ignored so existing data keeps it's meaning.
 type G struct {
-	UserID string
-	Group  Group
-	Level  Level
+	Tenant    string
+	UserID    string
+	Group     Group
+	Level     Level
+	Perms     PermSet
+	Effect    Effect
+	NotBefore time.Time
+	Expires   time.Time
+	Condition Condition `json:"-" bson:"-"`
 }
 
 // canUser implements Grant interface
 func (g G) canUser(u User, l Level) bool {
-	ugo := Level(0)
-	if g.UserID != "" && u.id == g.UserID {
-		ugo = ugo | l<<6
+	u.groups = u.groups.qualify(u.tenant)
+	return g.decide(check{ctx: context.Background(), user: u, action: RuneFromLevel(l)}).Allowed
+}
+
+// decide implements Grant interface
+func (g G) decide(c check) Decision {
+	return GL{g}.decide(c)
+}
+
+// grants implements Grant interface
+func (g G) grants() GL {
+	return GL{g}
+}
+
+// inactive returns the reason the grant is outside it's time bounds or it's condition does not allow
+// the context, if any
+func (g G) inactive(ctx context.Context) string {
+	now := TimeFromContext(ctx)
+	switch {
+	case !g.NotBefore.IsZero() && now.Before(g.NotBefore):
+		return "not valid before " + g.NotBefore.String()
+	case !g.Expires.IsZero() && !now.Before(g.Expires):
+		return "expired at " + g.Expires.String()
+	case g.Condition != nil && !g.Condition.Allows(ctx):
+		return "condition not met"
 	}
-	if g.Group != "" && u.groups.Contains(g.Group) {
-		ugo = ugo | l<<3
+	return ""
+}
+
+// matches returns the classes of the grant that match the given user and action
+func (g G) matches(u User, action rune) []Class {
+	var classes []Class
+	p := PermSetFromLevel(g.Level).union(g.Perms.extended())
+	if g.isUser(u) && p.Has(ClassUser, action) {
+		classes = append(classes, ClassUser)
+	}
+	if g.Group != "" && u.groups.Contains(MakeTenantGroup(g.Tenant, string(g.Group))) && p.Has(ClassGroup, action) {
+		classes = append(classes, ClassGroup)
+	}
+	if p.Has(ClassOther, action) {
+		classes = append(classes, ClassOther)
+	}
+	return classes
+}
+
+// isUser checks if the grant's user is the given user. IDs are compared with their tenant so a
+// user of another tenant never matches, even when CrossTenant lets the grant apply. Users without
+// a tenant never match grants of a tenant, whatever their ID.
+func (g G) isUser(u User) bool {
+	if g.UserID == "" || u.id == "" || (u.tenant == "" && g.Tenant != "") {
+		return false
 	}
-	ugo = ugo | l
-	return ugo&g.Level != 0
+	return qualify(u.tenant, u.id) == qualify(g.Tenant, g.UserID)
 }
 
 // GL type is a list of G that also implements the Grant interface
@@ -41,10 +135,41 @@ type GL []G
 
 // canUser implements Grant interface
 func (g GL) canUser(u User, l Level) bool {
-	for _, grant := range g {
-		if grant.canUser(u, l) {
-			return true
+	u.groups = u.groups.qualify(u.tenant)
+	return g.decide(check{ctx: context.Background(), user: u, action: RuneFromLevel(l)}).Allowed
+}
+
+// grants implements Grant interface
+func (g GL) grants() GL {
+	return g
+}
+
+// decide implements Grant interface. Every grant in the list is considered and the match with the
+// highest precedence decides. Ties go to the grant listed first. Superusers of a tenant are allowed
+// by the first grant of their tenant.
+func (g GL) decide(chk check) Decision {
+	d := Decision{}
+	best := precedence(ClassNone, Allow)
+	for i := range g {
+		if !chk.inTenant(g[i].Tenant) || g[i].inactive(chk.ctx) != "" {
+			continue
+		}
+		if chk.superTenant != "" && chk.superTenant == g[i].Tenant {
+			return Decision{Allowed: true, Superuser: true, Grant: &g[i]}
+		}
+		for _, c := range g[i].matches(chk.user, chk.action) {
+			p := precedence(c, g[i].Effect)
+			if p >= best {
+				continue
+			}
+			best = p
+			d = Decision{
+				Allowed: g[i].Effect == Allow,
+				Effect:  g[i].Effect,
+				Class:   c,
+				Grant:   &g[i],
+			}
 		}
 	}
-	return false
+	return d
 }
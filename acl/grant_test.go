@@ -0,0 +1,67 @@
+package acl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanUser(t *testing.T) {
+	assert := assert.New(t)
+	u := NewUser("u1", []string{"g1"})
+	cases := map[G]bool{
+		MakeGrant("u1", "", Level(0400)): true,
+		MakeGrant("u2", "", Level(0400)): false,
+		MakeGrant("", "g1", Level(0040)): true,
+		MakeGrant("", "g2", Level(0040)): false,
+		MakeGrant("", "", Level(0004)):   true,
+		MakeGrant("u1", "g1", Level(0)):  false,
+	}
+	for g, expected := range cases {
+		assert.Equal(expected, u.Can(Read, g), fmt.Sprintf("Expected %+v to result in %t", g, expected))
+	}
+}
+
+func TestDenyPrecedence(t *testing.T) {
+	assert := assert.New(t)
+	contractors := GL{
+		MakeGrant("", "contractors", LevelFromString("---r-----")),
+		MakeDenyGrant("x", "", LevelFromString("r--------")),
+	}
+	assert.True(NewUser("y", []string{"contractors"}).Can(Read, contractors))
+	assert.False(NewUser("x", []string{"contractors"}).Can(Read, contractors))
+
+	d := NewUser("x", []string{"contractors"}).Decide(Read, contractors)
+	assert.Equal(Deny, d.Effect)
+	assert.Equal(ClassUser, d.Class)
+	assert.Equal(&contractors[1], d.Grant)
+
+	// group deny beats a user allow
+	gl := GL{
+		MakeGrant("x", "", LevelFromString("r--------")),
+		MakeDenyGrant("", "contractors", LevelFromString("---r-----")),
+	}
+	d = NewUser("x", []string{"contractors"}).Decide(Read, gl)
+	assert.False(d.Allowed)
+	assert.Equal(ClassGroup, d.Class)
+
+	// an explicit allow beats a deny in the other bits
+	gl = GL{
+		MakeDenyGrant("", "", LevelFromString("------r--")),
+		MakeGrant("", "staff", LevelFromString("---r-----")),
+	}
+	assert.True(NewUser("x", []string{"staff"}).Can(Read, gl))
+	assert.False(NewUser("x", nil).Can(Read, gl))
+
+	// nothing matched
+	d = NewUser("x", nil).Decide(Write, gl)
+	assert.False(d.Allowed)
+	assert.Nil(d.Grant)
+	assert.Equal(ClassNone, d.Class)
+
+	// admin bypass
+	d = NewUser("x", []string{"admin"}).Decide(Read, contractors)
+	assert.True(d.Allowed)
+	assert.True(d.Superuser)
+}
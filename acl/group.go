@@ -1,5 +1,15 @@
 package acl
 
+import "strings"
+
+// Tenant qualification of group names and user IDs
+const (
+	// TenantSeparator separates the tenant from the name in tenant qualified groups and user IDs
+	TenantSeparator = ":"
+	// AnyTenant qualifies superuser groups and IDs that match users of every tenant
+	AnyTenant = "*"
+)
+
 // Group ...
 type Group string
 
@@ -20,10 +30,63 @@ func MakeGroups(n ...string) Groups {
 	return g
 }
 
+// MakeTenantGroup creates a new Group qualified with the given tenant, for example acme:admin.
+// Groups of users with a tenant are qualified the same way when registering parents in a
+// GroupRegistry or assigning roles.
+func MakeTenantGroup(tenant, name string) Group {
+	return Group(qualify(tenant, name))
+}
+
 func (g Group) String() string {
 	return string(g)
 }
 
+// Tenant returns the tenant of a tenant qualified group
+func (g Group) Tenant() string {
+	i := strings.Index(string(g), TenantSeparator)
+	if i == -1 {
+		return ""
+	}
+	return string(g)[:i]
+}
+
+// Name returns the group's name without it's tenant
+func (g Group) Name() string {
+	i := strings.Index(string(g), TenantSeparator)
+	return string(g)[i+1:]
+}
+
+// qualify returns the list with each group qualified with the given tenant
+func (g Groups) qualify(tenant string) Groups {
+	result := Groups{}
+	for _, v := range g {
+		result = append(result, MakeTenantGroup(tenant, string(v)))
+	}
+	return result
+}
+
+// unqualify returns the names of the groups in the given tenant
+func (g Groups) unqualify(tenant string) Groups {
+	if tenant == "" {
+		return g
+	}
+	result := Groups{}
+	for _, v := range g {
+		if v.Tenant() == tenant {
+			result = append(result, Group(v.Name()))
+		}
+	}
+	return result
+}
+
+// qualify prefixes a group name or user ID with the tenant unless already qualified with it
+func qualify(tenant, name string) string {
+	if tenant == "" || Group(name).Tenant() == tenant {
+		return name
+	}
+	return tenant + TenantSeparator + name
+}
+
 // Contains checks if groups list contains a given group
 func (g Groups) Contains(group Group) bool {
 	for _, v := range g {
@@ -50,10 +50,16 @@ func RuneFromLevel(l Level) rune {
 // For example:
 // rwx------ means read, write, and execute for user only. Group and other receive no access.
 // r--r--r-- means read access for all types with no write or execute access.
+// Characters beyond the 9th are ignored and missing characters grant nothing. Use ParseLevel to
+// reject malformed strings.
 func LevelFromString(l string) Level {
 	result := Level(0)
 	shift := uint(6)
-	for i, r := range []byte(l)[:9] {
+	b := []byte(l)
+	if len(b) > 9 {
+		b = b[:9]
+	}
+	for i, r := range b {
 		result = result | LevelFromRune(rune(r))<<shift
 
 		if (i+1)%3 == 0 {
@@ -84,6 +90,7 @@ func (l Level) String() string {
 // LevelFromExpression converts a permission express in the form of ugo+w or u+rw,g+r to it's corresponding
 // level value. This function has the accidental genius ability to process a complex expression in the form
 // of uwxgor which translates to ugo+r,u+wx though admitedly more complicated to read and understand.
+// Unknown characters are ignored. Use ParseLevel for strict parsing with errors.
 func LevelFromExpression(e string) Level {
 	u, g, o := LevelNone, LevelNone, LevelNone
 	expressions := strings.Split(e, ",")
@@ -52,3 +52,71 @@ func TestSetBSON(t *testing.T) {
 	}
 	_ = assert
 }
+
+func TestLevelFromStringShort(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(Level(0600), LevelFromString("rw----"))
+	assert.Equal(LevelNone, LevelFromString(""))
+}
+
+func TestParseLevel(t *testing.T) {
+	assert := assert.New(t)
+	cases := map[string]Level{
+		"0754":           Level(0754),
+		"754":            Level(0754),
+		"0":              LevelNone,
+		"rwxr-xr--":      Level(0754),
+		"u=rwx,g=rx,o=r": Level(0754),
+		"a+r":            Level(0444),
+		"+x":             Level(0111),
+		"ugo+w":          Level(0222),
+		"u+rw-w,go=r":    Level(0444),
+		"a=rwx,o-rwx":    Level(0770),
+	}
+	for expr, expected := range cases {
+		actual, err := ParseLevel(expr)
+		assert.NoError(err, expr)
+		assert.Equal(expected, actual, fmt.Sprintf("Expected %s to result in %#4o, got %#4o", expr, expected, actual))
+	}
+}
+
+func TestParseLevelErrors(t *testing.T) {
+	assert := assert.New(t)
+	cases := map[string]int{
+		"":         0,
+		"01777":    0,
+		"u":        1,
+		"u+q":      2,
+		"u+r,gw":   5,
+		"u+r,,o+r": 4,
+		"rwx":      0,
+		"z+r":      0,
+	}
+	for expr, pos := range cases {
+		_, err := ParseLevel(expr)
+		if assert.Error(err, expr) {
+			assert.Equal(pos, err.(ExpressionError).Pos, expr)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	assert := assert.New(t)
+	cases := map[string]Level{
+		"g-w":  Level(0754),
+		"o+w":  Level(0776),
+		"u=r":  Level(0474),
+		"a-x":  Level(0664),
+		"go=":  Level(0700),
+		"0600": Level(0600),
+	}
+	for expr, expected := range cases {
+		actual, err := Apply(Level(0774), expr)
+		assert.NoError(err, expr)
+		assert.Equal(expected, actual, fmt.Sprintf("Expected %s to result in %#4o, got %#4o", expr, expected, actual))
+	}
+
+	actual, err := Apply(Level(0774), "u-r,g+q")
+	assert.Error(err)
+	assert.Equal(Level(0774), actual, "Base level must be returned unchanged on error")
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/codeblanche/golibs/acl"
+	"github.com/labstack/echo"
+)
+
+// EchoConfig for echo middleware. Extractors receive the echo.Context so route params are available.
+// When Deny is nil an echo.HTTPError with the matching status is returned.
+type EchoConfig struct {
+	User       func(echo.Context) (*acl.User, error)
+	Grant      func(echo.Context) (acl.Grant, error)
+	Action     rune
+	Authorizer *acl.Authorizer
+	Deny       func(c echo.Context, status int, err error) error
+}
+
+// Echo creates echo middleware enforcing the given config. The user is stored in the request context
+// and can be retrieved by handlers with acl.FromContext(c.Request().Context()).
+func Echo(conf EchoConfig) echo.MiddlewareFunc {
+	deny := conf.Deny
+	if deny == nil {
+		deny = func(c echo.Context, status int, err error) error {
+			return echo.NewHTTPError(status)
+		}
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			u, err := conf.User(c)
+			if err == nil && u == nil {
+				err = ErrNoUser
+			}
+			if err != nil {
+				return deny(c, http.StatusUnauthorized, err)
+			}
+			g, err := conf.Grant(c)
+			if err != nil {
+				return deny(c, http.StatusInternalServerError, err)
+			}
+			if g == nil {
+				return deny(c, http.StatusNotFound, ErrNoGrant)
+			}
+			d := authorizer(conf.Authorizer).DecideContext(c.Request().Context(), *u, conf.Action, g)
+			if !d.Allowed {
+				return deny(c, http.StatusForbidden, DeniedError{Action: conf.Action, Decision: d})
+			}
+			r := c.Request()
+			c.SetRequest(r.WithContext(acl.NewContext(r.Context(), u)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/codeblanche/golibs/acl"
+)
+
+var (
+	// ErrNoUser is passed to the deny handler when the user extractor returns no user
+	ErrNoUser = errors.New("No user found for request")
+	// ErrNoGrant is passed to the deny handler when the grant resolver returns no grant
+	ErrNoGrant = errors.New("No grant found for request")
+)
+
+// DeniedError is passed to the deny handler when the user may not perform the route's action
+type DeniedError struct {
+	Action   rune
+	Decision acl.Decision
+}
+
+// Error implements error interface
+func (e DeniedError) Error() string {
+	return "Access " + e.Decision.String() + " for action " + string(e.Action)
+}
+
+// UserFunc extracts the user making the request
+type UserFunc func(*http.Request) (*acl.User, error)
+
+// GrantFunc resolves the grant of the resource addressed by the request
+type GrantFunc func(*http.Request) (acl.Grant, error)
+
+// DenyFunc writes the response for a rejected request. Status is 401 when no user could be
+// extracted, 500 when the grant could not be resolved, 404 when there is no grant and 403 when
+// access was denied.
+type DenyFunc func(w http.ResponseWriter, r *http.Request, status int, err error)
+
+// Config for net/http middleware
+type Config struct {
+	User       UserFunc
+	Grant      GrantFunc
+	Action     rune
+	Authorizer *acl.Authorizer
+	Deny       DenyFunc
+}
+
+// Require creates net/http middleware enforcing the given config. Grants are checked against the
+// request context so earlier middleware can add acl.WithAttributes for grant conditions. The user is
+// stored in the request context and can be retrieved by handlers with acl.FromContext.
+func Require(c Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return Handler(c, next)
+	}
+}
+
+// Handler wraps the next handler so it is only served when the config is satisfied
+func Handler(c Config, next http.Handler) http.Handler {
+	deny := c.Deny
+	if deny == nil {
+		deny = DefaultDeny
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, err := c.User(r)
+		if err == nil && u == nil {
+			err = ErrNoUser
+		}
+		if err != nil {
+			deny(w, r, http.StatusUnauthorized, err)
+			return
+		}
+		g, err := c.Grant(r)
+		if err != nil {
+			deny(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if g == nil {
+			deny(w, r, http.StatusNotFound, ErrNoGrant)
+			return
+		}
+		d := authorizer(c.Authorizer).DecideContext(r.Context(), *u, c.Action, g)
+		if !d.Allowed {
+			deny(w, r, http.StatusForbidden, DeniedError{Action: c.Action, Decision: d})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(acl.NewContext(r.Context(), u)))
+	})
+}
+
+// DefaultDeny responds with the status text only so no details of the decision are leaked
+func DefaultDeny(w http.ResponseWriter, r *http.Request, status int, err error) {
+	http.Error(w, http.StatusText(status), status)
+}
+
+// authorizer falls back to the acl.DefaultAuthorizer
+func authorizer(a *acl.Authorizer) *acl.Authorizer {
+	if a == nil {
+		return acl.DefaultAuthorizer
+	}
+	return a
+}
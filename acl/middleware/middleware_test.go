@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codeblanche/golibs/acl"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+var grant = acl.MakeGrant("owner", "", acl.LevelFromString("rw-------"))
+
+func user(r *http.Request) (*acl.User, error) {
+	switch id := r.Header.Get("X-User"); id {
+	case "":
+		return nil, nil
+	case "broken":
+		return nil, errors.New("broken")
+	default:
+		return acl.NewUser(id, nil), nil
+	}
+}
+
+func TestHandler(t *testing.T) {
+	assert := assert.New(t)
+	var seen *acl.User
+	h := Require(Config{
+		User:   user,
+		Grant:  func(*http.Request) (acl.Grant, error) { return grant, nil },
+		Action: acl.Write,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = acl.FromContext(r.Context())
+	}))
+
+	cases := map[string]int{
+		"owner":  http.StatusOK,
+		"other":  http.StatusForbidden,
+		"":       http.StatusUnauthorized,
+		"broken": http.StatusUnauthorized,
+	}
+	for id, expected := range cases {
+		seen = nil
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-User", id)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(expected, w.Code, id)
+		if expected == http.StatusOK {
+			assert.Equal(id, seen.ID())
+		} else {
+			assert.Nil(seen)
+		}
+	}
+}
+
+func TestHandlerDeny(t *testing.T) {
+	assert := assert.New(t)
+	var denied error
+	h := Handler(Config{
+		User:   user,
+		Grant:  func(*http.Request) (acl.Grant, error) { return nil, errors.New("not found") },
+		Action: acl.Read,
+		Deny: func(w http.ResponseWriter, r *http.Request, status int, err error) {
+			denied = err
+			w.WriteHeader(http.StatusNotFound)
+		},
+	}, http.NotFoundHandler())
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-User", "owner")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(http.StatusNotFound, w.Code)
+	assert.EqualError(denied, "not found")
+}
+
+func TestNoGrant(t *testing.T) {
+	assert := assert.New(t)
+	var denied error
+	h := Handler(Config{
+		User:   user,
+		Grant:  func(*http.Request) (acl.Grant, error) { return nil, nil },
+		Action: acl.Read,
+		Deny: func(w http.ResponseWriter, r *http.Request, status int, err error) {
+			denied = err
+			DefaultDeny(w, r, status, err)
+		},
+	}, http.NotFoundHandler())
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-User", "owner")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(http.StatusNotFound, w.Code)
+	assert.Equal(ErrNoGrant, denied)
+
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error { return nil }, Echo(EchoConfig{
+		User:   func(c echo.Context) (*acl.User, error) { return user(c.Request()) },
+		Grant:  func(c echo.Context) (acl.Grant, error) { return nil, nil },
+		Action: acl.Read,
+	}))
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestEcho(t *testing.T) {
+	assert := assert.New(t)
+	e := echo.New()
+	e.GET("/docs/:id", func(c echo.Context) error {
+		u, _ := acl.FromContext(c.Request().Context())
+		return c.String(http.StatusOK, u.ID())
+	}, Echo(EchoConfig{
+		User: func(c echo.Context) (*acl.User, error) { return user(c.Request()) },
+		Grant: func(c echo.Context) (acl.Grant, error) {
+			return acl.MakeGrant(c.Param("id"), "", acl.LevelFromString("r--------")), nil
+		},
+		Action: acl.Read,
+	}))
+
+	cases := map[string]int{
+		"owner": http.StatusOK,
+		"other": http.StatusForbidden,
+		"":      http.StatusUnauthorized,
+	}
+	for id, expected := range cases {
+		r := httptest.NewRequest("GET", "/docs/owner", nil)
+		r.Header.Set("X-User", id)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		assert.Equal(expected, w.Code, id)
+	}
+}
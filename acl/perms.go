@@ -0,0 +1,214 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Action is a named permission bit. The name is used in bson field names such as user_can_delete.
+type Action struct {
+	Rune rune
+	Name string
+}
+
+// ActionExistsError is returned when registering an action whose rune or name is already in use
+type ActionExistsError struct {
+	Action Action
+}
+
+// Error implements error interface
+func (e ActionExistsError) Error() string {
+	return fmt.Sprintf("Action %q (%c) conflicts with a registered action or expression syntax", e.Action.Name, e.Action.Rune)
+}
+
+var (
+	actionsMutex = sync.RWMutex{}
+	// actions are indexed by bit. The first three match the per class bits of Level.
+	actions = []Action{
+		{Execute, "execute"},
+		{Write, "write"},
+		{Read, "read"},
+	}
+)
+
+// RegisterAction registers an additional permission bit with it's own rune, for example
+// RegisterAction('d', "delete"). Registered actions can be used in PermSet strings and expressions,
+// with User.Can and in G.Perms. Applications must register actions in the same order on every start.
+func RegisterAction(r rune, name string) error {
+	actionsMutex.Lock()
+	defer actionsMutex.Unlock()
+
+	a := Action{Rune: r, Name: name}
+	if name == "" || strings.ContainsRune("ugoa+-=,01234567", r) || len(actions) == 32 {
+		return ActionExistsError{Action: a}
+	}
+	for _, one := range actions {
+		if one.Rune == r || one.Name == name {
+			return ActionExistsError{Action: a}
+		}
+	}
+	actions = append(actions, a)
+	return nil
+}
+
+// Actions returns the registered actions in the order they appear in PermSet strings: r, w, x
+// followed by additional actions in registration order
+func Actions() []Action {
+	actionsMutex.RLock()
+	defer actionsMutex.RUnlock()
+
+	return append([]Action{actions[2], actions[1], actions[0]}, actions[3:]...)
+}
+
+// actionBit returns the bit of a registered action rune
+func actionBit(r rune) (uint, bool) {
+	actionsMutex.RLock()
+	defer actionsMutex.RUnlock()
+
+	for i, a := range actions {
+		if a.Rune == r {
+			return uint(i), true
+		}
+	}
+	return 0, false
+}
+
+// actionName returns the name of a registered action rune
+func actionName(r rune) (string, bool) {
+	bit, ok := actionBit(r)
+	if !ok {
+		return "", false
+	}
+	actionsMutex.RLock()
+	defer actionsMutex.RUnlock()
+
+	return actions[bit].Name, true
+}
+
+// rwxBits masks the bits of a PermSet class shared with Level
+const rwxBits = 07
+
+// PermSet is an extensible set of permissions for the user, group and other classes. Bits 0 to 2
+// of each class match Level (x, w and r) and registered actions use the bits above them. PermSet
+// strings and bson documents use the same format as Level for the rwx actions.
+type PermSet struct {
+	User  uint32
+	Group uint32
+	Other uint32
+}
+
+// PermSetFromLevel converts a Level to a PermSet
+func PermSetFromLevel(l Level) PermSet {
+	return PermSet{
+		User:  uint32(l >> 6 & rwxBits),
+		Group: uint32(l >> 3 & rwxBits),
+		Other: uint32(l & rwxBits),
+	}
+}
+
+// ParsePermSet strictly parses a PermSet. See PermSet.Apply for the supported forms.
+func ParsePermSet(expr string) (PermSet, error) {
+	return PermSet{}.Apply(expr)
+}
+
+// Level returns the rwx permissions of the set
+func (p PermSet) Level() Level {
+	return Level(p.User&rwxBits)<<6 | Level(p.Group&rwxBits)<<3 | Level(p.Other&rwxBits)
+}
+
+// Has checks if the given class of the set includes the action
+func (p PermSet) Has(c Class, action rune) bool {
+	bit, ok := actionBit(action)
+	return ok && p.class(c)&(1<<bit) != 0
+}
+
+// IsZero checks if the set grants nothing
+func (p PermSet) IsZero() bool {
+	return p == PermSet{}
+}
+
+// extended returns the set without the rwx bits
+func (p PermSet) extended() PermSet {
+	return PermSet{User: p.User &^ rwxBits, Group: p.Group &^ rwxBits, Other: p.Other &^ rwxBits}
+}
+
+// union returns the permissions in either set
+func (p PermSet) union(o PermSet) PermSet {
+	return PermSet{User: p.User | o.User, Group: p.Group | o.Group, Other: p.Other | o.Other}
+}
+
+// class returns the bits of the given class
+func (p PermSet) class(c Class) uint32 {
+	switch c {
+	case ClassUser:
+		return p.User
+	case ClassGroup:
+		return p.Group
+	case ClassOther:
+		return p.Other
+	}
+	return 0
+}
+
+// String implements Stringer. Each class lists the runes of Actions or a dash, so a set without
+// registered actions has the same 9 character form as LevelToString.
+func (p PermSet) String() string {
+	result := ""
+	for _, c := range []Class{ClassUser, ClassGroup, ClassOther} {
+		for _, a := range Actions() {
+			if p.Has(c, a.Rune) {
+				result += string(a.Rune)
+				continue
+			}
+			result += "-"
+		}
+	}
+	return result
+}
+
+// GetBSON implements bson.Getter using the same field names as Level for the rwx actions
+func (p PermSet) GetBSON() (interface{}, error) {
+	m := bson.M{}
+	for _, c := range []Class{ClassUser, ClassGroup, ClassOther} {
+		for _, a := range Actions() {
+			if p.Has(c, a.Rune) {
+				m[c.String()+"_can_"+a.Name] = true
+			}
+		}
+	}
+	return m, nil
+}
+
+// SetBSON implements bson.Setter. Fields of actions that are not registered are ignored.
+func (p *PermSet) SetBSON(raw bson.Raw) error {
+	m := map[string]bool{}
+	err := raw.Unmarshal(&m)
+	if err != nil {
+		return err
+	}
+	*p = PermSet{}
+	for _, a := range Actions() {
+		bit, _ := actionBit(a.Rune)
+		for _, c := range []Class{ClassUser, ClassGroup, ClassOther} {
+			if m[c.String()+"_can_"+a.Name] {
+				p.set(c, p.class(c)|1<<bit)
+			}
+		}
+	}
+	return nil
+}
+
+// set replaces the bits of the given class
+func (p *PermSet) set(c Class, bits uint32) {
+	switch c {
+	case ClassUser:
+		p.User = bits
+	case ClassGroup:
+		p.Group = bits
+	case ClassOther:
+		p.Other = bits
+	}
+}
@@ -0,0 +1,114 @@
+package acl
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var registerDocActions sync.Once
+
+// docActions registers delete, share and manage once for all tests
+func docActions() {
+	registerDocActions.Do(func() {
+		RegisterAction('d', "delete")
+		RegisterAction('s', "share")
+		RegisterAction('m', "manage")
+	})
+}
+
+func TestRegisterAction(t *testing.T) {
+	assert := assert.New(t)
+	docActions()
+	assert.Error(RegisterAction('d', "destroy"))
+	assert.Error(RegisterAction('z', "delete"))
+	assert.Error(RegisterAction('u', "update"))
+	assert.Error(RegisterAction('q', ""))
+	runes := ""
+	for _, a := range Actions() {
+		runes += string(a.Rune)
+	}
+	assert.Equal("rwxdsm", runes)
+}
+
+func TestPermSetLevel(t *testing.T) {
+	assert := assert.New(t)
+	for _, l := range []Level{0777, 0754, 0640, 0001, 0} {
+		assert.Equal(l, PermSetFromLevel(l).Level(), fmt.Sprintf("Expected %#4o to round trip", l))
+	}
+}
+
+func TestParsePermSet(t *testing.T) {
+	assert := assert.New(t)
+	docActions()
+	p, err := ParsePermSet("u=rwds,g=r,o=")
+	assert.NoError(err)
+	assert.True(p.Has(ClassUser, 'd'))
+	assert.False(p.Has(ClassGroup, 'd'))
+	assert.Equal(Level(0640), p.Level())
+
+	assert.Equal("rw-ds-r-----------", p.String())
+	actual, err := ParsePermSet(p.String())
+	assert.NoError(err)
+	assert.Equal(p, actual)
+
+	legacy, err := ParsePermSet("rw-r-----")
+	assert.NoError(err)
+	assert.Equal(PermSetFromLevel(0640), legacy)
+
+	p, err = p.Apply("g+s,u-d")
+	assert.NoError(err)
+	assert.True(p.Has(ClassGroup, 's'))
+	assert.False(p.Has(ClassUser, 'd'))
+
+	_, err = ParsePermSet("u+q")
+	assert.Error(err)
+	_, err = ParseLevel("u+d")
+	assert.Error(err, "Level expressions only support rwx")
+}
+
+func TestPermSetBSON(t *testing.T) {
+	assert := assert.New(t)
+	docActions()
+	p, _ := ParsePermSet("u=rwd,g=rs")
+	b, err := bson.Marshal(bson.M{"p": p})
+	assert.NoError(err)
+
+	m := bson.M{}
+	assert.NoError(bson.Unmarshal(b, &m))
+	assert.Equal(bson.M{"user_can_read": true, "user_can_write": true, "user_can_delete": true,
+		"group_can_read": true, "group_can_share": true}, m["p"])
+
+	actual := struct{ P PermSet }{}
+	assert.NoError(bson.Unmarshal(b, &actual))
+	assert.Equal(p, actual.P)
+
+	// Level documents decode to the same permissions
+	b, err = bson.Marshal(bson.M{"p": Level(0754)})
+	assert.NoError(err)
+	assert.NoError(bson.Unmarshal(b, &actual))
+	assert.Equal(PermSetFromLevel(0754), actual.P)
+}
+
+func TestExtendedGrant(t *testing.T) {
+	assert := assert.New(t)
+	docActions()
+	g := MakeGrant("owner", "editors", LevelFromString("rw-rw-r--"))
+	g.Perms, _ = ParsePermSet("u=dsm,g=s")
+	owner, editor, other := NewUser("owner", nil), NewUser("e1", []string{"editors"}), NewUser("o1", nil)
+
+	assert.True(owner.Can('d', g))
+	assert.True(owner.Can('m', g))
+	assert.True(editor.Can('s', g))
+	assert.False(editor.Can('d', g))
+	assert.False(other.Can('s', g))
+	assert.True(other.Can(Read, g))
+
+	// rwx bits in Perms are ignored in favour of Level
+	g.Perms, _ = ParsePermSet("o=w")
+	assert.False(other.Can(Write, g))
+}
@@ -0,0 +1,96 @@
+package acl
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	// Registry is the default group hierarchy used when testing users against grants
+	Registry = NewGroupRegistry()
+)
+
+// GroupCycleError is returned when registering a parent would make a group its own ancestor
+type GroupCycleError struct {
+	Group  Group
+	Parent Group
+}
+
+// Error implements error interface
+func (e GroupCycleError) Error() string {
+	return fmt.Sprintf("Group %q cannot inherit from %q as it would create a cycle", e.Group, e.Parent)
+}
+
+// GroupRegistry holds the parent groups declared by each group. Members of a group are
+// implicitly members of all of it's ancestors so a grant to eng covers eng-backend and
+// eng-backend-payments once they declare eng as an ancestor.
+type GroupRegistry struct {
+	mutex   sync.RWMutex
+	parents map[Group]Groups
+}
+
+// NewGroupRegistry creates a new, empty GroupRegistry
+func NewGroupRegistry() *GroupRegistry {
+	return &GroupRegistry{
+		parents: map[Group]Groups{},
+	}
+}
+
+// RegisterGroup declares the parent groups of a group in the default Registry
+func RegisterGroup(name string, parents ...string) error {
+	return Registry.Register(MakeGroup(name), MakeGroups(parents...)...)
+}
+
+// Register declares the parent groups of a group. Parents are added to any previously registered.
+// Nothing is registered if any of the parents would create a cycle.
+func (r *GroupRegistry) Register(g Group, parents ...Group) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, p := range parents {
+		if r.ancestors(Groups{p}).Contains(g) {
+			return GroupCycleError{Group: g, Parent: p}
+		}
+	}
+	for _, p := range parents {
+		if !r.parents[g].Contains(p) {
+			r.parents[g] = append(r.parents[g], p)
+		}
+	}
+	return nil
+}
+
+// Parents returns the groups directly inherited by the given group
+func (r *GroupRegistry) Parents(g Group) Groups {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append(Groups{}, r.parents[g]...)
+}
+
+// Expand returns the given groups followed by all of their ancestors, each listed once
+func (r *GroupRegistry) Expand(groups Groups) Groups {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.ancestors(groups)
+}
+
+// ancestors walks the hierarchy breadth first. Groups already visited are skipped so a cycle
+// can never loop forever.
+func (r *GroupRegistry) ancestors(groups Groups) Groups {
+	result := Groups{}
+	seen := map[Group]bool{}
+	queue := append(Groups{}, groups...)
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		if seen[g] {
+			continue
+		}
+		seen[g] = true
+		result = append(result, g)
+		queue = append(queue, r.parents[g]...)
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryExpand(t *testing.T) {
+	assert := assert.New(t)
+	r := NewGroupRegistry()
+	assert.NoError(r.Register("eng-backend", "eng"))
+	assert.NoError(r.Register("eng-backend-payments", "eng-backend", "finance"))
+	assert.Equal(MakeGroups("eng-backend-payments", "eng-backend", "finance", "eng"), r.Expand(MakeGroups("eng-backend-payments")))
+	assert.Equal(MakeGroups("eng"), r.Expand(MakeGroups("eng")))
+}
+
+func TestRegistryCycle(t *testing.T) {
+	assert := assert.New(t)
+	r := NewGroupRegistry()
+	assert.NoError(r.Register("b", "a"))
+	assert.NoError(r.Register("c", "b"))
+	assert.Equal(GroupCycleError{Group: "a", Parent: "c"}, r.Register("a", "c"))
+	assert.Error(r.Register("a", "a"))
+	assert.Empty(r.Parents("a"))
+}
+
+func TestUserCanInherited(t *testing.T) {
+	assert := assert.New(t)
+	defer func(r *GroupRegistry) { Registry = r }(Registry)
+	Registry = NewGroupRegistry()
+	assert.NoError(RegisterGroup("eng-backend", "eng"))
+	assert.NoError(RegisterGroup("eng-backend-payments", "eng-backend"))
+
+	g := MakeGrant("", "eng", LevelFromString("---rw----"))
+	assert.True(NewUser("u1", []string{"eng-backend-payments"}).Can(Write, g))
+	assert.False(NewUser("u2", []string{"sales"}).Can(Write, g))
+}
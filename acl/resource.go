@@ -0,0 +1,121 @@
+package acl
+
+import "errors"
+
+// ErrNotPermitted is returned when a user may not change the ownership or mode of a resource
+var ErrNotPermitted = errors.New("Operation not permitted")
+
+// Owned is implemented by resources with Unix like ownership. Models embedding Resource implement it.
+type Owned interface {
+	Ownership() *Resource
+}
+
+// Resource defines Unix like ownership: one owner, one owning group and a mode. The mode's user bits
+// apply to the owner, group bits to members of the owning group and other bits to everyone. Unlike
+// Unix the bits are combined rather than the first matching class deciding, so an owner with mode
+// 0004 may still read through the other bits.
+type Resource struct {
+	Tenant string `json:"tenant,omitempty" bson:"tenant,omitempty"`
+	Owner  string `json:"owner" bson:"owner"`
+	Group  Group  `json:"group" bson:"group"`
+	Mode   Level  `json:"mode" bson:"mode"`
+}
+
+// MakeResource creates a new Resource
+func MakeResource(owner, group string, mode Level) Resource {
+	return Resource{
+		Owner: owner,
+		Group: MakeGroup(group),
+		Mode:  mode,
+	}
+}
+
+// Ownership implements Owned
+func (r *Resource) Ownership() *Resource {
+	return r
+}
+
+// Grant implements Granted
+func (r Resource) Grant() Grant {
+	return G{Tenant: r.Tenant, UserID: r.Owner, Group: r.Group, Level: r.Mode}
+}
+
+// canUser implements Grant interface
+func (r Resource) canUser(u User, l Level) bool {
+	return r.Grant().canUser(u, l)
+}
+
+// decide implements Grant interface
+func (r Resource) decide(c check) Decision {
+	return r.Grant().decide(c)
+}
+
+// grants implements Grant interface
+func (r Resource) grants() GL {
+	return r.Grant().grants()
+}
+
+// Chown transfers ownership of the resource using the DefaultAuthorizer. See Authorizer.Chown.
+func (r *Resource) Chown(u User, owner string) error {
+	return DefaultAuthorizer.Chown(u, r, owner)
+}
+
+// Chgrp changes the owning group of the resource using the DefaultAuthorizer. See Authorizer.Chgrp.
+func (r *Resource) Chgrp(u User, group string) error {
+	return DefaultAuthorizer.Chgrp(u, r, MakeGroup(group))
+}
+
+// Chmod changes the mode of the resource using the DefaultAuthorizer. See Authorizer.Chmod.
+func (r *Resource) Chmod(u User, expr string) error {
+	return DefaultAuthorizer.Chmod(u, r, expr)
+}
+
+// Chown transfers ownership of the resource to another user. Only the current owner and superusers
+// may transfer ownership.
+func (a *Authorizer) Chown(u User, o Owned, owner string) error {
+	r := o.Ownership()
+	if !a.owns(u, r) {
+		return ErrNotPermitted
+	}
+	r.Owner = owner
+	return nil
+}
+
+// Chgrp changes the owning group of the resource. Superusers may change to any group, the owner only
+// to groups it is a member of, including inherited groups.
+func (a *Authorizer) Chgrp(u User, o Owned, group Group) error {
+	r := o.Ownership()
+	if !a.owns(u, r) {
+		return ErrNotPermitted
+	}
+	u = a.resolve(u)
+	if _, super := a.superuser(u); !super && !u.groups.Contains(MakeTenantGroup(r.Tenant, string(group))) {
+		return ErrNotPermitted
+	}
+	r.Group = group
+	return nil
+}
+
+// Chmod applies a level expression to the mode of the resource. See Apply for the supported syntax.
+// Only the owner and superusers may change the mode.
+func (a *Authorizer) Chmod(u User, o Owned, expr string) error {
+	r := o.Ownership()
+	if !a.owns(u, r) {
+		return ErrNotPermitted
+	}
+	mode, err := Apply(r.Mode, expr)
+	if err != nil {
+		return err
+	}
+	r.Mode = mode
+	return nil
+}
+
+// owns checks if the user is the owner of the resource or a superuser of the resource's tenant
+func (a *Authorizer) owns(u User, r *Resource) bool {
+	if u.id != "" && u.id == r.Owner && u.tenant == r.Tenant {
+		return true
+	}
+	scope, super := a.superuser(a.resolve(u))
+	return super && (scope == "" || scope == r.Tenant)
+}
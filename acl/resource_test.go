@@ -0,0 +1,55 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type document struct {
+	Resource
+	Title string
+}
+
+func TestResourceCan(t *testing.T) {
+	assert := assert.New(t)
+	r := MakeResource("u1", "g1", Level(0640))
+	assert.True(NewUser("u1", nil).Can(Write, r))
+	assert.True(NewUser("u2", []string{"g1"}).Can(Read, r))
+	assert.False(NewUser("u2", []string{"g1"}).Can(Write, r))
+	assert.False(NewUser("u3", nil).Can(Read, r))
+	// bits are combined, the owner is not limited to the user bits
+	assert.True(NewUser("u1", nil).Can(Read, MakeResource("u1", "g1", Level(0004))))
+}
+
+func TestResourceChown(t *testing.T) {
+	assert := assert.New(t)
+	d := &document{Resource: MakeResource("u1", "g1", Level(0640))}
+	assert.Equal(ErrNotPermitted, d.Chown(*NewUser("u2", []string{"g1"}), "u2"))
+	assert.NoError(d.Chown(*NewUser("u1", nil), "u2"))
+	assert.Equal("u2", d.Owner)
+	assert.Equal(ErrNotPermitted, d.Chown(*NewUser("u1", nil), "u1"))
+	assert.NoError(d.Chown(*NewUser("root", []string{"admin"}), "u1"))
+	assert.Equal("u1", d.Owner)
+}
+
+func TestResourceChgrp(t *testing.T) {
+	assert := assert.New(t)
+	d := &document{Resource: MakeResource("u1", "g1", Level(0640))}
+	assert.Equal(ErrNotPermitted, d.Chgrp(*NewUser("u1", []string{"g1"}), "g2"))
+	assert.Equal(ErrNotPermitted, d.Chgrp(*NewUser("u2", []string{"g2"}), "g2"))
+	assert.NoError(d.Chgrp(*NewUser("u1", []string{"g2"}), "g2"))
+	assert.Equal(MakeGroup("g2"), d.Group)
+	assert.NoError(DefaultAuthorizer.Chgrp(*NewUser("root", []string{"admin"}), d, "g3"))
+	assert.Equal(MakeGroup("g3"), d.Group)
+}
+
+func TestResourceChmod(t *testing.T) {
+	assert := assert.New(t)
+	d := &document{Resource: MakeResource("u1", "g1", Level(0640))}
+	assert.Equal(ErrNotPermitted, d.Chmod(*NewUser("u2", []string{"g1"}), "g+w"))
+	assert.NoError(d.Chmod(*NewUser("u1", nil), "g+w,o+r"))
+	assert.Equal(Level(0664), d.Mode)
+	assert.Error(d.Chmod(*NewUser("u1", nil), "g+q"))
+	assert.Equal(Level(0664), d.Mode)
+}
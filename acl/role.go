@@ -0,0 +1,109 @@
+package acl
+
+import "sync"
+
+var (
+	// Roles is the default role registry used by User.HasPermission
+	Roles = NewRoleRegistry()
+)
+
+// Permission is a named right such as invoice:refund or post:publish
+type Permission string
+
+// Permissions ...
+type Permissions []Permission
+
+// MakePermissions creates a new Permissions
+func MakePermissions(n ...string) Permissions {
+	p := Permissions{}
+	for _, name := range n {
+		p = append(p, Permission(name))
+	}
+	return p
+}
+
+// Contains checks if permissions list contains a given permission
+func (p Permissions) Contains(perm Permission) bool {
+	for _, v := range p {
+		if v == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleRegistry holds named roles, the permissions they bundle and the users and groups they
+// are assigned to. Roles work alongside the rwx levels of G grants so both models can be used
+// at the same time.
+type RoleRegistry struct {
+	mutex  sync.RWMutex
+	roles  map[string]Permissions
+	users  map[string][]string
+	groups map[Group][]string
+}
+
+// NewRoleRegistry creates a new, empty RoleRegistry
+func NewRoleRegistry() *RoleRegistry {
+	return &RoleRegistry{
+		roles:  map[string]Permissions{},
+		users:  map[string][]string{},
+		groups: map[Group][]string{},
+	}
+}
+
+// DefineRole adds permissions to a role in the default Roles registry
+func DefineRole(role string, permissions ...string) {
+	Roles.Define(role, MakePermissions(permissions...)...)
+}
+
+// Define adds permissions to a role, creating the role if needed
+func (r *RoleRegistry) Define(role string, permissions ...Permission) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.roles[role]; !ok {
+		r.roles[role] = Permissions{}
+	}
+	for _, p := range permissions {
+		if !r.roles[role].Contains(p) {
+			r.roles[role] = append(r.roles[role], p)
+		}
+	}
+}
+
+// AssignUser assigns roles to the user with the given ID
+func (r *RoleRegistry) AssignUser(uid string, roles ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.users[uid] = append(r.users[uid], roles...)
+}
+
+// AssignGroup assigns roles to all members of the given group
+func (r *RoleRegistry) AssignGroup(g Group, roles ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.groups[g] = append(r.groups[g], roles...)
+}
+
+// Permissions returns every permission granted to the user through roles assigned to it's ID or
+// any of the given groups
+func (r *RoleRegistry) Permissions(uid string, groups Groups) Permissions {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	roles := append([]string{}, r.users[uid]...)
+	for _, g := range groups {
+		roles = append(roles, r.groups[g]...)
+	}
+	result := Permissions{}
+	for _, role := range roles {
+		for _, p := range r.roles[role] {
+			if !result.Contains(p) {
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
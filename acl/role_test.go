@@ -0,0 +1,34 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolePermissions(t *testing.T) {
+	assert := assert.New(t)
+	r := NewRoleRegistry()
+	r.Define("editor", "post:publish", "post:edit")
+	r.Define("billing-admin", "invoice:refund", "post:edit")
+	r.AssignUser("u1", "editor")
+	r.AssignGroup("billing", "billing-admin")
+
+	assert.Equal(MakePermissions("post:publish", "post:edit"), r.Permissions("u1", nil))
+	assert.Equal(MakePermissions("post:publish", "post:edit", "invoice:refund"), r.Permissions("u1", MakeGroups("billing")))
+	assert.Empty(r.Permissions("u2", MakeGroups("sales")))
+}
+
+func TestUserHasPermission(t *testing.T) {
+	assert := assert.New(t)
+	defer func(r *RoleRegistry, g *GroupRegistry) { Roles, Registry = r, g }(Roles, Registry)
+	Roles, Registry = NewRoleRegistry(), NewGroupRegistry()
+	DefineRole("billing-admin", "invoice:refund")
+	Roles.AssignGroup("finance", "billing-admin")
+	assert.NoError(RegisterGroup("finance-ops", "finance"))
+
+	assert.True(NewUser("u1", []string{"finance-ops"}).HasPermission("invoice:refund"))
+	assert.False(NewUser("u1", []string{"finance-ops"}).HasPermission("post:publish"))
+	assert.False(NewUser("u2", nil).HasPermission("invoice:refund"))
+	assert.True(NewUser("u3", []string{"admin"}).HasPermission("post:publish"))
+}
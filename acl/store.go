@@ -0,0 +1,110 @@
+package acl
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrNoGrants is returned by a GrantStore when no grants are stored for a resource
+	ErrNoGrants = errors.New("No grants stored for resource")
+	// ErrConditionalGrant is returned by a GrantStore when putting a grant with a Condition
+	ErrConditionalGrant = errors.New("Grants with a condition cannot be stored")
+)
+
+// GrantStore persists the grants of resources by resource ID. Conditions are code and cannot be
+// stored, so stores refuse grants with a Condition rather than return them as unconditional grants.
+type GrantStore interface {
+	// Get the grants of a resource or ErrNoGrants
+	Get(resourceID string) (GL, error)
+	// Put replaces the grants of a resource. Grants with a Condition are refused with
+	// ErrConditionalGrant and nothing is stored.
+	Put(resourceID string, grants GL) error
+	// Delete the grants of a resource. Deleting a resource without grants is not an error.
+	Delete(resourceID string) error
+	// Resources lists the IDs of resources the user may perform the action on
+	Resources(u User, action rune) ([]string, error)
+}
+
+// MemoryStore is a GrantStore holding grants in memory, mostly useful for tests. A nil Authorizer
+// falls back to the DefaultAuthorizer.
+type MemoryStore struct {
+	Authorizer *Authorizer
+	mutex      sync.RWMutex
+	grants     map[string]GL
+}
+
+// NewMemoryStore creates a new, empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		grants: map[string]GL{},
+	}
+}
+
+// Get implements GrantStore
+func (s *MemoryStore) Get(resourceID string) (GL, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	g, ok := s.grants[resourceID]
+	if !ok {
+		return nil, ErrNoGrants
+	}
+	return append(GL{}, g...), nil
+}
+
+// Put implements GrantStore
+func (s *MemoryStore) Put(resourceID string, grants GL) error {
+	if err := storable(grants); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.grants[resourceID] = append(GL{}, grants...)
+	return nil
+}
+
+// Delete implements GrantStore
+func (s *MemoryStore) Delete(resourceID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.grants, resourceID)
+	return nil
+}
+
+// Resources implements GrantStore. IDs are sorted.
+func (s *MemoryStore) Resources(u User, action rune) ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	a := authorizer(s.Authorizer)
+	ids := []string{}
+	for id, g := range s.grants {
+		if a.Can(u, action, g) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// storable checks that none of the grants has a Condition
+func storable(grants GL) error {
+	for _, g := range grants {
+		if g.Condition != nil {
+			return ErrConditionalGrant
+		}
+	}
+	return nil
+}
+
+// authorizer falls back to the DefaultAuthorizer
+func authorizer(a *Authorizer) *Authorizer {
+	if a == nil {
+		return DefaultAuthorizer
+	}
+	return a
+}
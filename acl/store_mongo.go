@@ -0,0 +1,119 @@
+package acl
+
+import (
+	"github.com/codeblanche/golibs/db/mongo"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Database and collection used by MongoStore
+var (
+	MongoDB         = "acl"
+	MongoCollection = "grants"
+)
+
+// MongoStore is a GrantStore using the db/mongo package's Session. Each resource is stored as a
+// document with a resource field holding it's ID and a grants array using the bson encoding of G.
+// A nil Authorizer falls back to the DefaultAuthorizer.
+type MongoStore struct {
+	Authorizer *Authorizer
+}
+
+// grantDoc is the mongo.Model a resource's grants are stored as
+type grantDoc struct {
+	ID       bson.ObjectId `bson:"_id,omitempty"`
+	Resource string        `bson:"resource"`
+	Grants   GL            `bson:"grants"`
+}
+
+// ObjectID implements mongo.Model
+func (d *grantDoc) ObjectID() mongo.OID {
+	if d.ID == "" {
+		return nil
+	}
+	return d.ID
+}
+
+// SetObjectID implements mongo.Model
+func (d *grantDoc) SetObjectID(id interface{}) {
+	if oid, ok := id.(bson.ObjectId); ok {
+		d.ID = oid
+	}
+}
+
+// DBName implements mongo.Model
+func (d *grantDoc) DBName() string {
+	return MongoDB
+}
+
+// CName implements mongo.Model
+func (d *grantDoc) CName() string {
+	return MongoCollection
+}
+
+// Index ensures the unique index on resource IDs that keeps concurrent Puts of a new resource from
+// creating more than one document. A non unique resource index created by earlier versions must be
+// dropped first.
+func (s MongoStore) Index() error {
+	return mongo.UniqueIndex(&grantDoc{}, "resource")
+}
+
+// Get implements GrantStore
+func (s MongoStore) Get(resourceID string) (GL, error) {
+	d, err := s.load(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return d.Grants, nil
+}
+
+// Put implements GrantStore with a single upsert on the resource ID
+func (s MongoStore) Put(resourceID string, grants GL) error {
+	if err := storable(grants); err != nil {
+		return err
+	}
+	if grants == nil {
+		grants = GL{}
+	}
+	return mongo.Upsert(&grantDoc{}, mongo.M{"resource": resourceID}, mongo.M{"$set": mongo.M{"grants": grants}})
+}
+
+// Delete implements GrantStore
+func (s MongoStore) Delete(resourceID string) error {
+	d, err := s.load(resourceID)
+	if err == ErrNoGrants {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return mongo.Remove(d)
+}
+
+// Resources implements GrantStore using Authorizer.QueryList. CrossTenant is not supported.
+func (s MongoStore) Resources(u User, action rune) ([]string, error) {
+	var docs []grantDoc
+	err := mongo.Find(mongo.M(authorizer(s.Authorizer).QueryList(u, action, "grants")), "resource", &docs)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, d := range docs {
+		ids = append(ids, d.Resource)
+	}
+	return ids, nil
+}
+
+// load the document of a resource
+func (s MongoStore) load(resourceID string) (*grantDoc, error) {
+	d := &grantDoc{}
+	err := mongo.One(mongo.M{"resource": resourceID}, d)
+	if err == mgo.ErrNotFound {
+		return nil, ErrNoGrants
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,94 @@
+package acl
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	assert := assert.New(t)
+	var s GrantStore = NewMemoryStore()
+
+	_, err := s.Get("doc1")
+	assert.Equal(ErrNoGrants, err)
+
+	assert.NoError(s.Put("doc1", GL{MakeGrant("u1", "", LevelFromString("rw-------"))}))
+	assert.NoError(s.Put("doc2", GL{MakeGrant("", "g1", LevelFromString("---r-----"))}))
+	assert.NoError(s.Put("doc3", GL{MakeGrant("u2", "", LevelFromString("rw-------"))}))
+	g, err := s.Get("doc1")
+	assert.NoError(err)
+	assert.Len(g, 1)
+
+	ids, err := s.Resources(*NewUser("u1", []string{"g1"}), Read)
+	assert.NoError(err)
+	assert.Equal([]string{"doc1", "doc2"}, ids)
+	ids, _ = s.Resources(*NewUser("u1", []string{"g1"}), Write)
+	assert.Equal([]string{"doc1"}, ids)
+
+	assert.NoError(s.Delete("doc1"))
+	assert.NoError(s.Delete("doc1"))
+	_, err = s.Get("doc1")
+	assert.Equal(ErrNoGrants, err)
+}
+
+func TestGrantDocBSON(t *testing.T) {
+	assert := assert.New(t)
+	expires := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	g := MakeDenyGrant("u1", "g1", LevelFromString("rw-rw----"))
+	g.Tenant = "acme"
+	g.Expires = expires
+	d := grantDoc{ID: bson.NewObjectId(), Resource: "doc1", Grants: GL{g, MakeGrant("", "", Level(0004))}}
+
+	b, err := bson.Marshal(d)
+	assert.NoError(err)
+	m := bson.M{}
+	assert.NoError(bson.Unmarshal(b, &m))
+	first := m["grants"].([]interface{})[0].(bson.M)
+	assert.Equal("u1", first["userid"])
+	assert.Equal("acme", first["tenant"])
+	assert.Equal(bson.M{"user_can_read": true, "user_can_write": true, "group_can_read": true, "group_can_write": true}, first["level"])
+
+	actual := grantDoc{}
+	assert.NoError(bson.Unmarshal(b, &actual))
+	assert.Equal("doc1", actual.Resource)
+	if assert.Len(actual.Grants, 2) {
+		assert.Equal(g.Level, actual.Grants[0].Level)
+		assert.Equal(Deny, actual.Grants[0].Effect)
+		assert.True(expires.Equal(actual.Grants[0].Expires))
+		assert.True(actual.Grants[1].Expires.IsZero())
+		assert.Equal(Level(0004), actual.Grants[1].Level)
+	}
+}
+
+func TestStoreParity(t *testing.T) {
+	assert := assert.New(t)
+	conditional := MakeGrant("", "", LevelFromString("r--r--r--"))
+	conditional.Condition = AttrIn("region", "eu")
+	expires := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	timed := MakeDenyGrant("u1", "g1", LevelFromString("rw-rw----"))
+	timed.Tenant, timed.Expires = "acme", expires
+
+	stores := map[string]GrantStore{"memory": NewMemoryStore(), "mongo": MongoStore{}}
+	for name, s := range stores {
+		assert.Equal(ErrConditionalGrant, s.Put("doc1", GL{timed, conditional}), name)
+	}
+	_, err := stores["memory"].Get("doc1")
+	assert.Equal(ErrNoGrants, err)
+
+	// grants read back from the memory store match those decoded from a stored document
+	assert.NoError(stores["memory"].Put("doc1", GL{timed}))
+	memory, _ := stores["memory"].Get("doc1")
+	b, err := bson.Marshal(grantDoc{Resource: "doc1", Grants: GL{timed}})
+	assert.NoError(err)
+	stored := grantDoc{}
+	assert.NoError(bson.Unmarshal(b, &stored))
+	if assert.Len(stored.Grants, 1) && assert.Len(memory, 1) {
+		assert.True(memory[0].Expires.Equal(stored.Grants[0].Expires))
+		memory[0].Expires, stored.Grants[0].Expires = time.Time{}, time.Time{}
+		assert.Equal(memory, stored.Grants)
+	}
+}
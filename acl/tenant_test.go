@@ -0,0 +1,116 @@
+package acl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantGroup(t *testing.T) {
+	assert := assert.New(t)
+	g := MakeTenantGroup("acme", "admin")
+	assert.Equal(Group("acme:admin"), g)
+	assert.Equal("acme", g.Tenant())
+	assert.Equal("admin", g.Name())
+	assert.Equal(g, MakeTenantGroup("acme", string(g)))
+	assert.Equal(Group("admin"), MakeTenantGroup("", "admin"))
+	assert.Equal("", Group("admin").Tenant())
+	assert.Equal("admin", Group("admin").Name())
+}
+
+func TestTenantGrant(t *testing.T) {
+	assert := assert.New(t)
+	g := MakeGrant("u1", "editors", LevelFromString("rw-rw-r--"))
+	g.Tenant = "acme"
+
+	assert.True(NewTenantUser("acme", "u1", nil).Can(Write, g))
+	assert.True(NewTenantUser("acme", "u2", []string{"editors"}).Can(Write, g))
+	assert.False(NewTenantUser("globex", "u1", []string{"editors"}).Can(Write, g))
+	assert.False(NewUser("u1", []string{"editors"}).Can(Write, g))
+
+	a := NewAuthorizer()
+	a.CrossTenant = func(from, to string) bool { return from == "globex" && to == "acme" }
+	assert.True(a.Can(*NewTenantUser("globex", "u1", nil), Read, g))
+	assert.False(a.Can(*NewTenantUser("globex", "u1", nil), Write, g), "Users never match across tenants")
+	assert.False(a.Can(*NewTenantUser("globex", "u2", []string{"editors"}), Write, g), "Groups never match across tenants")
+	assert.False(a.Can(*NewTenantUser("initech", "u1", nil), Read, g))
+	assert.True(a.Can(*NewTenantUser("acme", "u1", nil), Write, g))
+	a.CrossTenant = func(from, to string) bool { return true }
+	assert.False(a.Can(*NewUser("acme:u1", nil), Write, g))
+}
+
+func TestTenantSuperusers(t *testing.T) {
+	assert := assert.New(t)
+	acme := MakeGrant("owner", "", LevelFromString("rw-------"))
+	acme.Tenant = "acme"
+	global := MakeGrant("owner", "", LevelFromString("rw-------"))
+
+	// the default admin group only applies to users without a tenant
+	assert.False(NewTenantUser("acme", "u1", []string{"admin"}).Can(Write, acme))
+	assert.True(NewUser("u1", []string{"admin"}).Can(Write, acme))
+
+	a := NewAuthorizer()
+	a.SuperGroups = Groups{MakeTenantGroup(AnyTenant, "admin")}
+	a.SuperUsers = []string{"acme:root"}
+	admin := *NewTenantUser("acme", "u1", []string{"admin"})
+	assert.True(a.Can(admin, Write, acme))
+	assert.True(a.Decide(admin, Write, acme).Superuser)
+	assert.False(a.Can(admin, Write, global))
+	assert.False(a.Can(*NewTenantUser("globex", "u1", []string{"admin"}), Write, acme))
+	assert.False(a.Can(*NewUser("u1", []string{"admin"}), Write, acme))
+	assert.True(a.Can(*NewTenantUser("acme", "root", nil), Write, acme))
+	assert.False(a.Can(*NewTenantUser("globex", "root", nil), Write, acme))
+
+	// names carrying a tenant never match that tenant's entries for users without a tenant
+	assert.False(a.IsSuperuser(*NewUser("acme:root", nil)))
+	assert.False(a.Can(*NewUser("acme:root", nil), Write, global))
+	scoped := NewAuthorizer()
+	scoped.SuperGroups = Groups{MakeTenantGroup("acme", "admin")}
+	assert.True(scoped.IsSuperuser(admin))
+	assert.False(scoped.IsSuperuser(*NewUser("u1", []string{"acme:admin"})))
+	assert.False(scoped.Can(*NewUser("u1", []string{"acme:admin"}), Write, acme))
+	assert.False(scoped.Can(*NewUser("u1", []string{"acme:admin"}), Write, global))
+
+	r := MakeResource("owner", "g1", Level(0600))
+	r.Tenant = "acme"
+	assert.NoError(a.Chmod(admin, &r, "g+r"))
+	assert.Equal(ErrNotPermitted, a.Chmod(*NewTenantUser("globex", "u1", []string{"admin"}), &r, "o+r"))
+	assert.Equal(ErrNotPermitted, a.Chmod(*NewTenantUser("globex", "owner", nil), &r, "o+r"))
+}
+
+func TestTenantRegistries(t *testing.T) {
+	assert := assert.New(t)
+	a := NewAuthorizer()
+	a.Groups, a.Roles = NewGroupRegistry(), NewRoleRegistry()
+	assert.NoError(a.Groups.Register("acme:eng-backend", "acme:eng"))
+	a.Roles.Define("deployer", "deploy")
+	a.Roles.AssignGroup("acme:eng", "deployer")
+
+	g := MakeGrant("", "eng", LevelFromString("---r-----"))
+	g.Tenant = "acme"
+	assert.True(a.Can(*NewTenantUser("acme", "u1", []string{"eng-backend"}), Read, g))
+	assert.True(a.HasPermission(*NewTenantUser("acme", "u1", []string{"eng-backend"}), "deploy"))
+	assert.False(a.HasPermission(*NewTenantUser("globex", "u1", []string{"eng-backend"}), "deploy"))
+	assert.False(a.HasPermission(*NewUser("u1", []string{"acme:eng"}), "deploy"))
+	a.Roles.AssignUser("acme:u9", "deployer")
+	assert.True(a.HasPermission(*NewTenantUser("acme", "u9", nil), "deploy"))
+	assert.False(a.HasPermission(*NewUser("acme:u9", nil), "deploy"))
+	assert.False(a.HasPermission(*NewUser("u1", []string{"eng"}), "deploy"))
+}
+
+func TestTenantUserSerialisation(t *testing.T) {
+	assert := assert.New(t)
+	u, err := UserFromClaims(map[string]interface{}{"tenant": "acme", "sub": "u1", "groups": "g1"})
+	assert.NoError(err)
+	assert.Equal(NewTenantUser("acme", "u1", []string{"g1"}), u)
+	_, err = UserFromClaims(map[string]interface{}{"tenant": 1, "sub": "u1"})
+	assert.Error(err)
+
+	b, err := json.Marshal(u)
+	assert.NoError(err)
+	assert.JSONEq(`{"tenant":"acme","id":"u1","groups":["g1"]}`, string(b))
+	actual := User{}
+	assert.NoError(json.Unmarshal(b, &actual))
+	assert.Equal("acme", actual.Tenant())
+}
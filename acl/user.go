@@ -1,7 +1,15 @@
 package acl
 
+import (
+	"context"
+	"encoding/json"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
 // User type defines the model for an access controlled user/entity
 type User struct {
+	tenant string
 	id     string
 	groups Groups
 }
@@ -14,7 +22,85 @@ func NewUser(id string, groups []string) *User {
 	}
 }
 
-// Can tests a user agains a action and grant
+// NewTenantUser creates a new User belonging to the given tenant. The user's groups are groups of
+// that tenant and grants of other tenants do not apply to it.
+func NewTenantUser(tenant, id string, groups []string) *User {
+	u := NewUser(id, groups)
+	u.tenant = tenant
+	return u
+}
+
+// Can tests a user agains a action and grant using the DefaultAuthorizer
 func (u User) Can(action rune, g Grant) bool {
-	return u.groups.Contains(adminGroup) || g.canUser(u, LevelFromRune(action))
+	return DefaultAuthorizer.Can(u, action, g)
+}
+
+// Decide tests a user against an action and grant using the DefaultAuthorizer and reports which
+// grant decided the outcome
+func (u User) Decide(action rune, g Grant) Decision {
+	return DefaultAuthorizer.Decide(u, action, g)
+}
+
+// CanContext tests a user against an action and grant in the given context using the DefaultAuthorizer
+func (u User) CanContext(ctx context.Context, action rune, g Grant) bool {
+	return DefaultAuthorizer.CanContext(ctx, u, action, g)
+}
+
+// HasPermission tests a user against a named permission using the DefaultAuthorizer
+func (u User) HasPermission(permission string) bool {
+	return DefaultAuthorizer.HasPermission(u, permission)
+}
+
+// Tenant returns the user's tenant
+func (u User) Tenant() string {
+	return u.tenant
+}
+
+// ID returns the user's ID
+func (u User) ID() string {
+	return u.id
+}
+
+// Groups returns a copy of the groups the user is a direct member of
+func (u User) Groups() Groups {
+	return append(Groups{}, u.groups...)
+}
+
+// Need to create a new type for marshalling to prevent recursion
+type serialUser struct {
+	Tenant string `json:"tenant,omitempty" bson:"tenant,omitempty"`
+	ID     string `json:"id" bson:"id"`
+	Groups Groups `json:"groups,omitempty" bson:"groups,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (u User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(serialUser{Tenant: u.tenant, ID: u.id, Groups: u.groups})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (u *User) UnmarshalJSON(b []byte) error {
+	var t serialUser
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+	u.tenant, u.id, u.groups = t.Tenant, t.ID, t.Groups
+	return nil
+}
+
+// GetBSON implements bson.Getter
+func (u User) GetBSON() (interface{}, error) {
+	return serialUser{Tenant: u.tenant, ID: u.id, Groups: u.groups}, nil
+}
+
+// SetBSON implements bson.Setter
+func (u *User) SetBSON(raw bson.Raw) error {
+	var t serialUser
+	err := raw.Unmarshal(&t)
+	if err != nil {
+		return err
+	}
+	u.tenant, u.id, u.groups = t.Tenant, t.ID, t.Groups
+	return nil
 }
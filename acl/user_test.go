@@ -0,0 +1,63 @@
+package acl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserJSON(t *testing.T) {
+	assert := assert.New(t)
+	u := NewUser("u1", []string{"g1", "g2"})
+	b, err := json.Marshal(u)
+	assert.NoError(err)
+	assert.JSONEq(`{"id":"u1","groups":["g1","g2"]}`, string(b))
+
+	actual := User{}
+	assert.NoError(json.Unmarshal(b, &actual))
+	assert.Equal(*u, actual)
+}
+
+func TestUserBSON(t *testing.T) {
+	assert := assert.New(t)
+	u := NewUser("u1", []string{"g1"})
+	b, err := bson.Marshal(u)
+	assert.NoError(err)
+
+	m := bson.M{}
+	assert.NoError(bson.Unmarshal(b, &m))
+	assert.Equal("u1", m["id"])
+
+	actual := User{}
+	assert.NoError(bson.Unmarshal(b, &actual))
+	assert.Equal("u1", actual.ID())
+	assert.Equal(MakeGroups("g1"), actual.Groups())
+}
+
+func TestUserFromClaims(t *testing.T) {
+	assert := assert.New(t)
+	cases := map[string]map[string]interface{}{
+		"list":   {"sub": "u1", "groups": []interface{}{"g1", "g2"}},
+		"slice":  {"sub": "u1", "groups": []string{"g1", "g2"}},
+		"string": {"sub": "u1", "groups": "g1, g2"},
+	}
+	for name, claims := range cases {
+		u, err := UserFromClaims(claims)
+		assert.NoError(err, name)
+		assert.Equal(NewUser("u1", []string{"g1", "g2"}), u, name)
+	}
+
+	u, err := UserFromClaimsKeys(map[string]interface{}{"uid": "u1"}, "uid", "roles")
+	assert.NoError(err)
+	assert.Empty(u.Groups())
+
+	_, err = UserFromClaims(map[string]interface{}{"groups": "g1"})
+	assert.Equal(ClaimError{Claim: "sub"}, err)
+	_, err = UserFromClaims(map[string]interface{}{"sub": ""})
+	assert.EqualError(err, `Claim "sub" is missing`)
+	_, err = UserFromClaims(map[string]interface{}{"sub": "u1", "groups": 42})
+	assert.Error(err)
+}
@@ -0,0 +1,38 @@
+// Command configreport prints every effective key of a config, it's value and the layer that
+// supplied it. Secrets are masked.
+//
+//	configreport -root ./config -env dev -prefix APP
+//
+// The report is built without the service's config struct, see config.Provenance. Services that
+// want defaults and env tags included can call config.Provenance with their struct instead.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codeblanche/golibs/config"
+)
+
+func main() {
+	root := flag.String("root", ".", "directory holding the config files")
+	env := flag.String("env", "prod", "env to report")
+	prefix := flag.String("prefix", "", "prefix of environment variable overrides, none if empty")
+	local := flag.Bool("local", false, "layer the local config file on top of the env chain")
+	source := flag.String("source", "", "directory with one key per file layered on top of the files")
+	flag.Parse()
+
+	config.EnvPrefix = *prefix
+	config.LoadLocal = *local
+	if *source != "" {
+		config.Sources = append(config.Sources, config.NewDirSource(*source))
+	}
+	report, err := config.Provenance(*root, *env, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, strings.TrimSpace(err.Error()))
+		os.Exit(1)
+	}
+	fmt.Print(report)
+}
@@ -1,32 +1,132 @@
 package config
 
 import (
-	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
+	"strings"
 
-	"github.com/BurntSushi/toml"
 	"github.com/codeblanche/golibs/logr"
-	"github.com/codeblanche/golibs/slice"
 )
 
-// Load TOML config files from given root dir into conf interface{}. Load loads prod.toml, stage.toml, test.toml,
-// and dev.toml in this order so configurations can be inherited and overriden. Load stops after loading the file
-// matching the given env value: prod|stage|test|dev.
-func Load(root, env string, conf interface{}) {
-	file := fmt.Sprintf("%s.toml", env)
-	avail := []string{"prod.toml", "stage.toml", "test.toml", "dev.toml"}
-	// default to prod
-	if !slice.Strings(avail).Contains(file) {
-		file = avail[0]
+// debugf logs the loaded config
+var debugf = logr.Debugf
+
+// Load config files from given root dir into conf interface{}. Load loads the files of every env in the chain of the
+// given env in DefaultEnvs, root first, so configurations can be inherited and overriden. With the default chain
+// Load loads prod, stage, test, and dev files in this order and stops after loading the file matching the given env
+// value: prod|stage|test|dev. An env that is not defined in DefaultEnvs is reported as an UnknownEnvError.
+//
+// When LoadLocal is set, a local file is loaded after the chain.
+//
+// Files are found by their extension in any of the registered Formats, e.g. prod.toml, stage.yaml or dev.json. An
+// env may only have one config file, an env with files in more than one format is reported as an AmbiguousError
+// and none of them are loaded.
+//
+// Fields of conf left at their zero value are first set with ApplyDefaults so every layer, including explicit zero
+// values such as false or 0, overrides the defaults. Nil pointer tables get their defaults too and are left nil when
+// no layer changes them.
+//
+// Missing files are skipped. Files that cannot be looked up, e.g. for lack of permission, or decoded are reported as
+// a FileError and keys that do not match any field of conf as an UndecodedError. All problems found are returned
+// together as an ErrorList, in which case conf may be partially populated and should not be used.
+//
+// The values of Sources are applied on top of the files. When EnvPrefix is set, environment variables are applied
+// on top of those with ApplyEnv. Finally references to secrets are resolved with ResolveSecrets and the result is
+// checked with Validate. Secrets are redacted when the loaded config is logged.
+func Load(root, env string, conf interface{}) error {
+	return load(root, env, conf, nil)
+}
+
+// load implements Load and records the layer setting each key in the trace
+func load(root, env string, conf interface{}, tr *trace) error {
+	chain, err := envChain(env)
+	if err != nil {
+		return err
 	}
 	logr.Infof("Loading config for env %s", env)
-	for _, f := range avail {
-		f = path.Join(root, f)
-		toml.DecodeFile(f, conf)
-		// break after
-		if f == file {
-			break
+	tables := allocDefaultTables(conf)
+	errl := ErrorList{}.append(applyDefaults(conf, tr))
+	for _, e := range chain {
+		if err := loadEnv(root, e, conf, tr); err != nil {
+			errl = append(errl, err)
+		}
+	}
+	for _, s := range Sources {
+		errl = errl.append(applySource(s, conf, tr))
+	}
+	if EnvPrefix != "" {
+		errl = errl.append(applyEnv(EnvPrefix, conf, tr))
+	}
+	releaseDefaultTables(tables)
+	var secrets []string
+	if len(errl) == 0 {
+		secrets, err = ResolveSecrets(conf)
+		errl = errl.append(err)
+		tr.secret(secrets...)
+	}
+	if len(errl) == 0 {
+		errl = errl.append(Validate(conf))
+	}
+	if len(errl) > 0 {
+		return errl
+	}
+	debugf("%s", Redact(conf, secrets))
+	return nil
+}
+
+// envChain returns the envs whose files are loaded for env, including the local file if enabled
+func envChain(env string) ([]string, error) {
+	chain, err := DefaultEnvs.Chain(env)
+	if err != nil {
+		return nil, err
+	}
+	if LoadLocal {
+		chain = append(chain, "local")
+	}
+	return chain, nil
+}
+
+// loadEnv decodes the config file of a single env into conf, if there is one
+func loadEnv(root, env string, conf interface{}, tr *trace) error {
+	files := []string{}
+	for _, ext := range Formats() {
+		p := path.Join(root, env+"."+ext)
+		if _, err := os.Stat(p); err == nil {
+			files = append(files, p)
+		} else if !os.IsNotExist(err) {
+			return FileError{File: p, Err: err}
+		}
+	}
+	switch len(files) {
+	case 0:
+		logr.Debugf("Config file for env %s not found, skipping", env)
+		return nil
+	case 1:
+		return loadFile(files[0], conf, tr)
+	}
+	return AmbiguousError{Env: env, Files: files}
+}
+
+// loadFile decodes a single config file into conf with the decoder registered for it's extension. A nil
+// conf only traces the file.
+func loadFile(file string, conf interface{}, tr *trace) error {
+	dec, _ := decoder(strings.TrimPrefix(path.Ext(file), "."))
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return FileError{File: file, Err: err}
+	}
+	if conf != nil {
+		keys, err := dec(data, conf)
+		if err != nil {
+			return fileError(file, err)
+		}
+		if len(keys) > 0 {
+			return UndecodedError{File: file, Keys: keys}
 		}
 	}
-	logr.Debugf("%+v", conf)
+	if err := tr.file(file, dec, data); err != nil {
+		return fileError(file, err)
+	}
+	return nil
 }
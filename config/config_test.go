@@ -0,0 +1,102 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	Name  string
+	Port  int
+	Debug bool
+}
+
+// writeConfigs writes the given files into a new temp dir and returns it's path
+func writeConfigs(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "golibs-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(path.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"prod.toml": "name = \"prod\"\nport = 80\n",
+		"test.toml": "port = 8080\n",
+		"dev.toml":  "debug = true\n",
+	})
+	defer os.RemoveAll(dir)
+
+	tests := map[string]testConfig{
+		"prod":  {Name: "prod", Port: 80},
+		"stage": {Name: "prod", Port: 80},
+		"test":  {Name: "prod", Port: 8080},
+		"dev":   {Name: "prod", Port: 8080, Debug: true},
+	}
+	for env, expected := range tests {
+		conf := testConfig{}
+		assert.NoError(Load(dir, env, &conf), env)
+		assert.Equal(expected, conf, env)
+	}
+	assert.Equal(UnknownEnvError{Env: "staging"}, Load(dir, "staging", &testConfig{}))
+}
+
+func TestLoadErrors(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"prod.toml":  "name = \"prod\"\nport = 80\n",
+		"stage.toml": "name = \"stage\"\nport = \"eighty\"\n",
+		"test.toml":  "name = \"test\"\nport = \n",
+		"dev.toml":   "nmae = \"dev\"\n[server]\nhost = \"localhost\"\n",
+	})
+	defer os.RemoveAll(dir)
+
+	err := Load(dir, "dev", &testConfig{})
+	if !assert.IsType(ErrorList{}, err) {
+		return
+	}
+	errl := err.(ErrorList)
+	if !assert.Len(errl, 3) {
+		return
+	}
+
+	assert.IsType(FileError{}, errl[0])
+	assert.Equal(path.Join(dir, "stage.toml"), errl[0].(FileError).File)
+	assert.Equal(2, errl[0].(FileError).Line)
+	assert.Equal("port", errl[0].(FileError).Key)
+
+	assert.IsType(FileError{}, errl[1])
+	assert.Equal(path.Join(dir, "test.toml"), errl[1].(FileError).File)
+	assert.Equal(2, errl[1].(FileError).Line)
+
+	assert.Equal(UndecodedError{File: path.Join(dir, "dev.toml"), Keys: []string{"nmae", "server", "server.host"}}, errl[2])
+}
+
+func TestLoadNoFiles(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, nil)
+	defer os.RemoveAll(dir)
+
+	conf := testConfig{Port: 1}
+	assert.NoError(Load(dir, "dev", &conf))
+	assert.Equal(testConfig{Port: 1}, conf)
+	assert.NoError(Load(path.Join(dir, "missing"), "dev", &conf))
+
+	// a root that cannot be looked into is not the same as a root without files
+	ioutil.WriteFile(path.Join(dir, "file"), nil, 0644)
+	err := Load(path.Join(dir, "file"), "prod", &conf)
+	if assert.IsType(ErrorList{}, err) && assert.Len(err, 1) && assert.IsType(FileError{}, err.(ErrorList)[0]) {
+		assert.Equal(path.Join(dir, "file", "prod.json"), err.(ErrorList)[0].(FileError).File)
+	}
+}
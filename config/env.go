@@ -0,0 +1,215 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// EnvPrefix enables environment variable overrides in Load when not empty. See ApplyEnv.
+	EnvPrefix = ""
+
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// EnvError describes an environment variable whose value cannot be converted to the type of the
+// config field it overrides
+type EnvError struct {
+	Var   string
+	Value string
+	Err   error
+}
+
+// Error implements error interface
+func (e EnvError) Error() string {
+	return fmt.Sprintf("config: env %s=%q: %s", e.Var, e.Value, e.Err)
+}
+
+// ApplyEnv overrides fields of conf, which must be a pointer to a struct, with environment variables.
+// Variable names are derived from the path of the field: the prefix followed by the toml key, or
+// field name, of each nested struct and of the field, upper cased and joined by underscores. With
+// prefix APP the field for the toml key host in table [db] is set by APP_DB_HOST.
+//
+// An env tag names the variable of a field in full, without the prefix, and env:"-" ignores the
+// field. Strings, bools, ints, uints, floats, time.Duration and encoding.TextUnmarshaler are
+// converted from the variable's value, numbers are always decimal. Slices of those are read from a
+// comma separated list.
+// Unset variables leave fields untouched, set but empty ones clear them.
+func ApplyEnv(prefix string, conf interface{}) error {
+	return applyEnv(prefix, conf, nil)
+}
+
+// applyEnv applies the environment variables and records the fields set in the trace
+func applyEnv(prefix string, conf interface{}, tr *trace) error {
+	v := reflect.ValueOf(conf)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: parameter 'conf' must be a pointer to a struct")
+	}
+	var errl ErrorList
+	applyEnvStruct(envName(prefix), "", v.Elem(), &errl, tr)
+	return errorList(errl)
+}
+
+// applyEnvStruct sets the fields of the struct s, found at the given key, from variables starting with
+// the given name
+func applyEnvStruct(name, key string, s reflect.Value, errl *ErrorList, tr *trace) {
+	t := s.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("env")
+		if tag == "-" {
+			continue
+		}
+		v := s.Field(i)
+		field := joinKey(key, tomlKey(f))
+		varName := joinEnv(name, envName(tomlKey(f)))
+		if tag != "" {
+			varName = tag
+		}
+		if isTable(v.Type()) {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					n := reflect.New(v.Type().Elem())
+					applyEnvStruct(varName, field, n.Elem(), errl, tr)
+					if !reflect.DeepEqual(n.Elem().Interface(), reflect.Zero(v.Type().Elem()).Interface()) {
+						v.Set(n)
+					}
+					continue
+				}
+				v = v.Elem()
+			}
+			applyEnvStruct(varName, field, v, errl, tr)
+			continue
+		}
+		value, ok := os.LookupEnv(varName)
+		if !ok {
+			continue
+		}
+		if err := setValue(v, value); err != nil {
+			*errl = append(*errl, EnvError{Var: varName, Value: value, Err: err})
+			continue
+		}
+		tr.set(field, "env "+varName)
+	}
+}
+
+// tomlKey returns the key of the field in toml files
+func tomlKey(f reflect.StructField) string {
+	if key := strings.Split(f.Tag.Get("toml"), ",")[0]; key != "" {
+		return key
+	}
+	return f.Name
+}
+
+// joinKey joins a key to the key of it's table
+func joinKey(table, key string) string {
+	if table == "" {
+		return key
+	}
+	return table + "." + key
+}
+
+// isTable checks if values of the type are nested structs rather than single values
+func isTable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(textUnmarshaler)
+}
+
+// setValue converts s to the type of v and sets it. An empty s sets the zero value, or an empty slice.
+func setValue(v reflect.Value, s string) error {
+	if s == "" && v.Kind() != reflect.Slice {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		n := reflect.New(v.Type().Elem())
+		if err := setValue(n.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(n)
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshaler) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		items := []string{}
+		if strings.TrimSpace(s) != "" {
+			items = strings.Split(s, ",")
+		}
+		l := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(l.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		v.Set(l)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// envName upper cases a key and replaces anything but letters and digits with underscores
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, key)
+}
+
+// joinEnv joins variable name segments with an underscore, skipping an empty prefix
+func joinEnv(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type envConfig struct {
+	Name    string
+	Debug   bool
+	Timeout time.Duration
+	Hosts   []string
+	Ports   []int
+	Ratio   float64
+	IP      net.IP
+	Secret  string `env:"SECRET_TOKEN"`
+	Ignored string `env:"-"`
+	DB      struct {
+		Host     string `toml:"host"`
+		MaxConns uint16 `toml:"max-conns"`
+	} `toml:"db"`
+	Cache *struct {
+		TTL time.Duration
+	}
+}
+
+// setenv sets the given environment variables and returns a func restoring them
+func setenv(vars map[string]string) func() {
+	for k, v := range vars {
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k := range vars {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	assert := assert.New(t)
+	defer setenv(map[string]string{
+		"APP_NAME":         "env",
+		"APP_DEBUG":        "true",
+		"APP_TIMEOUT":      "1m30s",
+		"APP_HOSTS":        "a, b,c",
+		"APP_PORTS":        "80,443",
+		"APP_RATIO":        "0.5",
+		"APP_IP":           "10.0.0.1",
+		"SECRET_TOKEN":     "s3cret",
+		"APP_IGNORED":      "nope",
+		"APP_DB_HOST":      "db.local",
+		"APP_DB_MAX_CONNS": "010",
+		"APP_CACHE_TTL":    "5s",
+	})()
+
+	conf := envConfig{Name: "file", Ignored: "file"}
+	assert.NoError(ApplyEnv("app", &conf))
+	assert.Equal("env", conf.Name)
+	assert.True(conf.Debug)
+	assert.Equal(90*time.Second, conf.Timeout)
+	assert.Equal([]string{"a", "b", "c"}, conf.Hosts)
+	assert.Equal([]int{80, 443}, conf.Ports)
+	assert.Equal(0.5, conf.Ratio)
+	assert.Equal("10.0.0.1", conf.IP.String())
+	assert.Equal("s3cret", conf.Secret)
+	assert.Equal("file", conf.Ignored)
+	assert.Equal("db.local", conf.DB.Host)
+	assert.Equal(uint16(10), conf.DB.MaxConns)
+	if assert.NotNil(conf.Cache) {
+		assert.Equal(5*time.Second, conf.Cache.TTL)
+	}
+}
+
+func TestApplyEnvUnset(t *testing.T) {
+	assert := assert.New(t)
+	defer setenv(map[string]string{
+		"APP_HOSTS":        "",
+		"APP_DEBUG":        "",
+		"APP_TIMEOUT":      "",
+		"APP_IP":           "",
+		"APP_DB_MAX_CONNS": "",
+	})()
+
+	conf := envConfig{Name: "file", Hosts: []string{"a"}, Debug: true, Timeout: time.Second, IP: net.IPv4(10, 0, 0, 1)}
+	conf.DB.MaxConns = 10
+	assert.NoError(ApplyEnv("APP", &conf))
+	assert.Equal("file", conf.Name)
+	assert.Equal([]string{}, conf.Hosts)
+	assert.False(conf.Debug)
+	assert.Zero(conf.Timeout)
+	assert.Nil(conf.IP)
+	assert.Zero(conf.DB.MaxConns)
+	assert.Nil(conf.Cache)
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	assert := assert.New(t)
+	defer setenv(map[string]string{
+		"APP_DEBUG":        "maybe",
+		"APP_PORTS":        "80,http",
+		"APP_TIMEOUT":      "10",
+		"APP_DB_MAX_CONNS": "0x10",
+	})()
+
+	err := ApplyEnv("APP", &envConfig{})
+	if assert.IsType(ErrorList{}, err) && assert.Len(err, 4) {
+		assert.Equal("APP_DEBUG", err.(ErrorList)[0].(EnvError).Var)
+		assert.Equal("APP_TIMEOUT", err.(ErrorList)[1].(EnvError).Var)
+		assert.Equal("APP_PORTS", err.(ErrorList)[2].(EnvError).Var)
+		assert.Equal("APP_DB_MAX_CONNS", err.(ErrorList)[3].(EnvError).Var)
+	}
+	assert.Error(ApplyEnv("APP", envConfig{}))
+}
+
+func TestLoadEnv(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{"prod.toml": "name = \"prod\"\nport = 80\n"})
+	defer os.RemoveAll(dir)
+	defer setenv(map[string]string{"APP_PORT": "8080"})()
+
+	conf := testConfig{}
+	assert.NoError(Load(dir, "prod", &conf))
+	assert.Equal(80, conf.Port)
+
+	EnvPrefix = "APP"
+	defer func() { EnvPrefix = "" }()
+	assert.NoError(Load(dir, "prod", &conf))
+	assert.Equal(testConfig{Name: "prod", Port: 8080}, conf)
+}
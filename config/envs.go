@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	// DefaultEnvs is the env chain used by Load. It defines prod, stage inheriting from prod, test
+	// inheriting from stage and dev inheriting from test.
+	DefaultEnvs = NewEnvs()
+
+	// LoadLocal makes Load layer a local config file, e.g. local.toml, on top of the env chain. The
+	// local file is meant for developer overrides and should never be committed.
+	LoadLocal = false
+)
+
+func init() {
+	DefaultEnvs.Define("prod", "")
+	DefaultEnvs.Define("stage", "prod")
+	DefaultEnvs.Define("test", "stage")
+	DefaultEnvs.Define("dev", "test")
+}
+
+// UnknownEnvError is returned when loading or inheriting from an env that was never defined
+type UnknownEnvError struct {
+	Env string
+}
+
+// Error implements error interface
+func (e UnknownEnvError) Error() string {
+	return fmt.Sprintf("config: unknown env %q", e.Env)
+}
+
+// EnvCycleError is returned when defining a parent would make an env inherit from itself
+type EnvCycleError struct {
+	Env    string
+	Parent string
+}
+
+// Error implements error interface
+func (e EnvCycleError) Error() string {
+	return fmt.Sprintf("config: env %q cannot inherit from %q as it would create a cycle", e.Env, e.Parent)
+}
+
+// Envs holds the known envs and the env each of them inherits from so chains such as
+// base → prod → prod-eu can be loaded.
+type Envs struct {
+	mutex   sync.RWMutex
+	parents map[string]string
+}
+
+// NewEnvs creates a new, empty Envs
+func NewEnvs() *Envs {
+	return &Envs{
+		parents: map[string]string{},
+	}
+}
+
+// DefineEnv defines an env and the env it inherits from in DefaultEnvs
+func DefineEnv(env, parent string) error {
+	return DefaultEnvs.Define(env, parent)
+}
+
+// Define defines an env and the env it inherits from, replacing any previous definition. An empty
+// parent starts a new chain. The parent must already be defined.
+func (e *Envs) Define(env, parent string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if parent != "" {
+		chain, err := e.chain(parent)
+		if err != nil {
+			return err
+		}
+		for _, c := range chain {
+			if c == env {
+				return EnvCycleError{Env: env, Parent: parent}
+			}
+		}
+	}
+	e.parents[env] = parent
+	return nil
+}
+
+// Chain returns the envs to load for the given env, starting at the root of it's chain and ending
+// with the env itself
+func (e *Envs) Chain(env string) ([]string, error) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	return e.chain(env)
+}
+
+// chain walks the parents of env up to the root of it's chain
+func (e *Envs) chain(env string) ([]string, error) {
+	chain := []string{}
+	for env != "" {
+		parent, ok := e.parents[env]
+		if !ok {
+			return nil, UnknownEnvError{Env: env}
+		}
+		chain = append([]string{env}, chain...)
+		env = parent
+	}
+	return chain, nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvsChain(t *testing.T) {
+	assert := assert.New(t)
+	e := NewEnvs()
+	assert.NoError(e.Define("base", ""))
+	assert.NoError(e.Define("prod", "base"))
+	assert.NoError(e.Define("prod-eu", "prod"))
+	assert.NoError(e.Define("stage", "base"))
+
+	tests := map[string][]string{
+		"base":    {"base"},
+		"prod":    {"base", "prod"},
+		"prod-eu": {"base", "prod", "prod-eu"},
+		"stage":   {"base", "stage"},
+	}
+	for env, expected := range tests {
+		chain, err := e.Chain(env)
+		assert.NoError(err, env)
+		assert.Equal(expected, chain, env)
+	}
+
+	_, err := e.Chain("staging")
+	assert.Equal(UnknownEnvError{Env: "staging"}, err)
+	assert.Equal(UnknownEnvError{Env: "staging"}, e.Define("staging-eu", "staging"))
+	assert.Equal(EnvCycleError{Env: "base", Parent: "prod-eu"}, e.Define("base", "prod-eu"))
+	assert.Equal(EnvCycleError{Env: "prod", Parent: "prod"}, e.Define("prod", "prod"))
+
+	chain, _ := e.Chain("prod-eu")
+	assert.Equal([]string{"base", "prod", "prod-eu"}, chain)
+}
+
+func TestDefaultEnvs(t *testing.T) {
+	assert := assert.New(t)
+	chain, err := DefaultEnvs.Chain("dev")
+	assert.NoError(err)
+	assert.Equal([]string{"prod", "stage", "test", "dev"}, chain)
+}
+
+func TestLoadChain(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"base.toml":    "name = \"base\"\nport = 80\n",
+		"prod-eu.toml": "name = \"eu\"\n",
+		"local.toml":   "debug = true\n",
+	})
+	defer os.RemoveAll(dir)
+	defaults := DefaultEnvs
+	DefaultEnvs = NewEnvs()
+	defer func() { DefaultEnvs = defaults }()
+	assert.NoError(DefineEnv("base", ""))
+	assert.NoError(DefineEnv("prod-eu", "base"))
+	assert.Equal(UnknownEnvError{Env: "dev"}, Load(dir, "dev", &testConfig{}))
+
+	conf := testConfig{}
+	assert.NoError(Load(dir, "prod-eu", &conf))
+	assert.Equal(testConfig{Name: "eu", Port: 80}, conf)
+
+	LoadLocal = true
+	defer func() { LoadLocal = false }()
+	conf = testConfig{}
+	assert.NoError(Load(dir, "prod-eu", &conf))
+	assert.Equal(testConfig{Name: "eu", Port: 80, Debug: true}, conf)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FileError describes a config file that exists but could not be decoded. Line and Key are set
+// when the decoder reports them.
+type FileError struct {
+	File string
+	Line int
+	Key  string
+	Err  error
+}
+
+// Error implements error interface
+func (e FileError) Error() string {
+	msg := "config: " + e.File
+	if e.Line > 0 {
+		msg += fmt.Sprintf(" line %d", e.Line)
+	}
+	if e.Key != "" {
+		msg += fmt.Sprintf(" key %q", e.Key)
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// UndecodedError lists the keys of a config file, or of a Source, that did not match any field of
+// the config struct, usually because a setting is misspelled
+type UndecodedError struct {
+	File string
+	Keys []string
+}
+
+// Error implements error interface
+func (e UndecodedError) Error() string {
+	return fmt.Sprintf("config: %s has unknown keys: %s", e.File, strings.Join(e.Keys, ", "))
+}
+
+// AmbiguousError is returned when an env has config files in more than one format
+type AmbiguousError struct {
+	Env   string
+	Files []string
+}
+
+// Error implements error interface
+func (e AmbiguousError) Error() string {
+	return fmt.Sprintf("config: env %s has more than one config file: %s", e.Env, strings.Join(e.Files, ", "))
+}
+
+// ErrorList collection of multiple errors
+type ErrorList []error
+
+// Error implements error interface
+func (e ErrorList) Error() string {
+	err := ""
+	for _, one := range e {
+		err += one.Error() + "\n"
+	}
+	return err
+}
+
+// append adds err to the list, flattening another ErrorList
+func (e ErrorList) append(err error) ErrorList {
+	if el, ok := err.(ErrorList); ok {
+		return append(e, el...)
+	} else if err != nil {
+		return append(e, err)
+	}
+	return e
+}
+
+// errorList returns nil for an empty list so it can be returned as an error
+func errorList(errl ErrorList) error {
+	if len(errl) == 0 {
+		return nil
+	}
+	return errl
+}
+
+var (
+	// lineKey matches the position toml reports in errors that are not a toml.ParseError
+	lineKey = regexp.MustCompile(`line (\d+) \(last key "([^"]*)"\): `)
+	// lineOnly matches the position reported by other decoders such as yaml
+	lineOnly = regexp.MustCompile(`line (\d+): `)
+)
+
+// fileError converts a decoding error to a FileError
+func fileError(file string, err error) FileError {
+	fe := FileError{File: file, Err: err}
+	switch t := err.(type) {
+	case FileError:
+		t.File = file
+		return t
+	case toml.ParseError:
+		fe.Line, fe.Key = t.Position.Line, t.LastKey
+		fe.Err = errors.New(t.Message)
+	default:
+		msg := err.Error()
+		if m := lineKey.FindStringSubmatchIndex(msg); m != nil {
+			fe.Line, _ = strconv.Atoi(msg[m[2]:m[3]])
+			fe.Key = msg[m[4]:m[5]]
+			fe.Err = errors.New(strings.TrimPrefix(msg[m[1]:], "toml: "))
+		} else if m := lineOnly.FindStringSubmatch(msg); m != nil {
+			fe.Line, _ = strconv.Atoi(m[1])
+		}
+	}
+	return fe
+}
@@ -0,0 +1,113 @@
+// Package flags provides feature flags configured in config files. Flags are turned on for
+// everyone, for a stable percentage of users or for allow-listed users and groups.
+//
+//	[flags.new-checkout]
+//	percent = 25
+//	groups = ["beta"]
+//
+// Flags are usually a field of the config struct, tagged toml:"flags", and kept up
+// to date with Set.Subscribe when the config is watched.
+package flags
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/codeblanche/golibs/config"
+	"github.com/codeblanche/golibs/slice"
+)
+
+// Flag configures a single feature flag. A flag is on for a user when it is enabled, the user or
+// one of it's groups is allow-listed or the user falls within the rollout percentage.
+type Flag struct {
+	Enabled bool     `toml:"enabled"`
+	Percent float64  `toml:"percent"`
+	Users   []string `toml:"users"`
+	Groups  []string `toml:"groups"`
+}
+
+// Flags maps flag names to their configuration
+type Flags map[string]Flag
+
+// On checks if the flag is on for everyone
+func (f Flag) On() bool {
+	return f.Enabled || f.Percent >= 100
+}
+
+// OnFor checks if the flag is on for the given user. Users are assigned to the rollout
+// percentage by a hash of the flag name and user ID so a user keeps it's assignment while the
+// percentage grows and different flags roll out to different users.
+func (f Flag) OnFor(name, uid string, groups []string) bool {
+	if f.On() {
+		return true
+	}
+	if uid != "" && slice.Strings(f.Users).Contains(uid) {
+		return true
+	}
+	for _, g := range groups {
+		if slice.Strings(f.Groups).Contains(g) {
+			return true
+		}
+	}
+	return uid != "" && f.Percent > 0 && float64(bucket(name, uid)) < f.Percent*100
+}
+
+// Set holds the current flags and can be queried and updated concurrently
+type Set struct {
+	value atomic.Value
+}
+
+// New creates a new Set holding the given flags
+func New(f Flags) *Set {
+	s := &Set{}
+	s.Update(f)
+	return s
+}
+
+// Update replaces the flags of the set
+func (s *Set) Update(f Flags) {
+	if f == nil {
+		f = Flags{}
+	}
+	s.value.Store(f)
+}
+
+// Flags returns the current flags. The map must not be modified.
+func (s *Set) Flags() Flags {
+	return s.value.Load().(Flags)
+}
+
+// On checks if the named flag is on for everyone. Unknown flags are off.
+func (s *Set) On(name string) bool {
+	return s.Flags()[name].On()
+}
+
+// OnFor checks if the named flag is on for the given user. Unknown flags are off.
+func (s *Set) OnFor(name, uid string, groups []string) bool {
+	return s.Flags()[name].OnFor(name, uid, groups)
+}
+
+// Subscribe updates the set with the flags returned by get whenever the watched config is
+// reloaded. get receives the config as returned by Watcher.Config. The set is subscribed before it
+// is updated with the current config so no reload is missed.
+func (s *Set) Subscribe(w *config.Watcher, get func(conf interface{}) Flags) {
+	mutex := sync.Mutex{}
+	update := func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		s.Update(get(w.Config()))
+	}
+	w.Subscribe(func(old, new interface{}) {
+		update()
+	})
+	update()
+}
+
+// bucket returns the stable rollout bucket, 0 to 9999, of the user for the flag
+func bucket(name, uid string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + uid))
+	return h.Sum32() % 10000
+}
@@ -0,0 +1,104 @@
+package flags
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/codeblanche/golibs/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagOnFor(t *testing.T) {
+	assert := assert.New(t)
+	tests := map[string]struct {
+		flag     Flag
+		uid      string
+		groups   []string
+		expected bool
+	}{
+		"off":            {Flag{}, "u1", nil, false},
+		"enabled":        {Flag{Enabled: true}, "", nil, true},
+		"full rollout":   {Flag{Percent: 100}, "", nil, true},
+		"allowed user":   {Flag{Users: []string{"u1"}}, "u1", nil, true},
+		"other user":     {Flag{Users: []string{"u1"}}, "u2", nil, false},
+		"allowed group":  {Flag{Groups: []string{"beta"}}, "u2", []string{"staff", "beta"}, true},
+		"other group":    {Flag{Groups: []string{"beta"}}, "u2", []string{"staff"}, false},
+		"anonymous user": {Flag{Percent: 99.99}, "", nil, false},
+	}
+	for name, test := range tests {
+		assert.Equal(test.expected, test.flag.OnFor("f", test.uid, test.groups), name)
+	}
+}
+
+func TestFlagRollout(t *testing.T) {
+	assert := assert.New(t)
+	on := func(f Flag, name string) map[string]bool {
+		users := map[string]bool{}
+		for i := 0; i < 10000; i++ {
+			uid := fmt.Sprintf("user-%d", i)
+			if f.OnFor(name, uid, nil) {
+				users[uid] = true
+			}
+		}
+		return users
+	}
+
+	ten := on(Flag{Percent: 10}, "a")
+	fifty := on(Flag{Percent: 50}, "a")
+	assert.InDelta(1000, len(ten), 150)
+	assert.InDelta(5000, len(fifty), 250)
+	for uid := range ten {
+		assert.True(fifty[uid], uid)
+	}
+	assert.NotEqual(ten, on(Flag{Percent: 10}, "b"))
+	assert.Equal(ten, on(Flag{Percent: 10}, "a"))
+}
+
+func TestSet(t *testing.T) {
+	assert := assert.New(t)
+	s := New(nil)
+	assert.False(s.On("new-checkout"))
+
+	s.Update(Flags{
+		"new-checkout": {Enabled: true},
+		"dark-mode":    {Groups: []string{"beta"}},
+	})
+	assert.True(s.On("new-checkout"))
+	assert.False(s.On("dark-mode"))
+	assert.True(s.OnFor("dark-mode", "u1", []string{"beta"}))
+	assert.False(s.OnFor("unknown", "u1", []string{"beta"}))
+}
+
+type flagsConfig struct {
+	Flags Flags `toml:"flags"`
+}
+
+func TestSetSubscribe(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "golibs-flags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "prod.toml")
+	ioutil.WriteFile(file, []byte("[flags.dark-mode]\nusers = [\"u1\"]\n"), 0644)
+
+	w, err := config.Watch(dir, "prod", &flagsConfig{})
+	if !assert.NoError(err) {
+		return
+	}
+	w.Close()
+	s := New(nil)
+	s.Subscribe(w, func(conf interface{}) Flags {
+		return conf.(*flagsConfig).Flags
+	})
+	assert.True(s.OnFor("dark-mode", "u1", nil))
+	assert.False(s.On("dark-mode"))
+
+	ioutil.WriteFile(file, []byte("[flags.dark-mode]\nenabled = true\n"), 0644)
+	assert.NoError(w.Reload())
+	assert.True(s.On("dark-mode"))
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Decoder decodes the content of a config file into conf and returns the keys of the file that did
+// not match any field of conf. A Decoder may return a FileError to report the line or key of an error,
+// the file is filled in by Load.
+type Decoder func(data []byte, conf interface{}) (undecoded []string, err error)
+
+var (
+	formatMutex = sync.RWMutex{}
+	formats     = map[string]Decoder{
+		"toml": DecodeTOML,
+		"json": DecodeJSON,
+		"yaml": DecodeYAML,
+		"yml":  DecodeYAML,
+	}
+)
+
+// RegisterFormat registers a decoder for config files with the given extension, replacing any
+// decoder registered for it before. toml, json, yaml and yml are registered by default.
+func RegisterFormat(ext string, d Decoder) {
+	formatMutex.Lock()
+	formats[strings.TrimPrefix(ext, ".")] = d
+	formatMutex.Unlock()
+}
+
+// Formats returns the sorted extensions of the registered formats
+func Formats() []string {
+	formatMutex.RLock()
+	defer formatMutex.RUnlock()
+
+	exts := []string{}
+	for ext := range formats {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// decoder returns the decoder registered for the extension
+func decoder(ext string) (Decoder, bool) {
+	formatMutex.RLock()
+	defer formatMutex.RUnlock()
+
+	d, ok := formats[ext]
+	return d, ok
+}
+
+// DecodeTOML decodes TOML config files
+func DecodeTOML(data []byte, conf interface{}) ([]string, error) {
+	md, err := toml.Decode(string(data), conf)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for _, k := range md.Undecoded() {
+		keys = append(keys, k.String())
+	}
+	return keys, nil
+}
+
+// DecodeJSON decodes JSON config files. Keys are matched to fields the same way as in TOML files.
+func DecodeJSON(data []byte, conf interface{}) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	m := map[string]interface{}{}
+	if err := dec.Decode(&m); err != nil {
+		if e, ok := err.(*json.SyntaxError); ok {
+			return nil, FileError{Line: bytes.Count(data[:e.Offset], []byte("\n")) + 1, Err: err}
+		}
+		return nil, err
+	}
+	return decodeMap(m, conf)
+}
+
+// DecodeYAML decodes YAML config files. Keys are matched to fields the same way as in TOML files.
+func DecodeYAML(data []byte, conf interface{}) ([]string, error) {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return decodeMap(m, conf)
+}
+
+// decodeMap decodes the generic values of a JSON or YAML file into conf by way of TOML, so toml tags,
+// durations and undecoded keys are handled the same for every format. Errors keep the key but not the
+// line, which would point into the TOML document.
+func decodeMap(m map[string]interface{}, conf interface{}) ([]string, error) {
+	buf := bytes.Buffer{}
+	if err := toml.NewEncoder(&buf).Encode(generic(m)); err != nil {
+		return nil, err
+	}
+	keys, err := DecodeTOML(buf.Bytes(), conf)
+	if err != nil {
+		fe := fileError("", err)
+		return nil, FileError{Key: fe.Key, Err: fe.Err}
+	}
+	return keys, nil
+}
+
+// generic converts the values decoded from JSON or YAML to the types TOML can encode. Maps get
+// string keys, JSON numbers become integers or floats and null values are dropped.
+func generic(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, v := range t {
+			if v != nil {
+				m[k] = generic(v)
+			}
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, v := range t {
+			if v != nil {
+				m[fmt.Sprint(k)] = generic(v)
+			}
+		}
+		return m
+	case []interface{}:
+		l := []interface{}{}
+		for _, v := range t {
+			if v != nil {
+				l = append(l, generic(v))
+			}
+		}
+		return l
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
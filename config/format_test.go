@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFormats(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"prod.toml":  "name = \"prod\"\nport = 80\n",
+		"stage.yaml": "port: 8000\n",
+		"test.yml":   "debug: true\n",
+		"dev.json":   `{"name": "dev"}`,
+	})
+	defer os.RemoveAll(dir)
+
+	tests := map[string]testConfig{
+		"prod":  {Name: "prod", Port: 80},
+		"stage": {Name: "prod", Port: 8000},
+		"test":  {Name: "prod", Port: 8000, Debug: true},
+		"dev":   {Name: "dev", Port: 8000, Debug: true},
+	}
+	for env, expected := range tests {
+		conf := testConfig{}
+		assert.NoError(Load(dir, env, &conf), env)
+		assert.Equal(expected, conf, env)
+	}
+}
+
+func TestLoadFormatErrors(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"prod.toml":  "name = \"prod\"\n",
+		"prod.json":  `{"name": "prod"}`,
+		"stage.yaml": "name: stage\nnmae: stage\n",
+		"test.json":  `{"port": 80, "debug": true, "nmae": "test"}`,
+	})
+	defer os.RemoveAll(dir)
+
+	err := Load(dir, "test", &testConfig{})
+	if !assert.IsType(ErrorList{}, err) || !assert.Len(err, 3) {
+		return
+	}
+	errl := err.(ErrorList)
+	assert.Equal(AmbiguousError{Env: "prod", Files: []string{path.Join(dir, "prod.json"), path.Join(dir, "prod.toml")}}, errl[0])
+	assert.Equal(UndecodedError{File: path.Join(dir, "stage.yaml"), Keys: []string{"nmae"}}, errl[1])
+	assert.Equal(UndecodedError{File: path.Join(dir, "test.json"), Keys: []string{"nmae"}}, errl[2])
+}
+
+func TestFormatParity(t *testing.T) {
+	assert := assert.New(t)
+	type db struct {
+		MaxConns int           `toml:"max-conns"`
+		Timeout  time.Duration `toml:"timeout"`
+	}
+	type conf struct {
+		Name  string
+		Hosts []string
+		DB    db `toml:"db"`
+	}
+	expected := conf{Name: "app", Hosts: []string{"a", "b"}, DB: db{MaxConns: 10, Timeout: 30 * time.Second}}
+	tests := map[string]struct {
+		dec       Decoder
+		valid     string
+		undecoded string
+		invalid   string
+	}{
+		"toml": {DecodeTOML,
+			"name = \"app\"\nhosts = [\"a\", \"b\"]\n[db]\nmax-conns = 10\ntimeout = \"30s\"\n",
+			"name = \"app\"\nnmae = \"app\"\n[db]\nmax_conns = 10\n",
+			"[db]\nmax-conns = \"ten\"\n"},
+		"json": {DecodeJSON,
+			`{"name": "app", "hosts": ["a", "b"], "db": {"max-conns": 10, "timeout": "30s"}}`,
+			`{"name": "app", "nmae": "app", "db": {"max_conns": 10}}`,
+			`{"db": {"max-conns": "ten"}}`},
+		"yaml": {DecodeYAML,
+			"name: app\nhosts: [a, b]\ndb:\n  max-conns: 10\n  timeout: 30s\n",
+			"name: app\nnmae: app\ndb:\n  max_conns: 10\n",
+			"db:\n  max-conns: ten\n"},
+	}
+	for name, test := range tests {
+		actual := conf{}
+		keys, err := test.dec([]byte(test.valid), &actual)
+		assert.NoError(err, name)
+		assert.Empty(keys, name)
+		assert.Equal(expected, actual, name)
+
+		keys, err = test.dec([]byte(test.undecoded), &conf{})
+		assert.NoError(err, name)
+		sort.Strings(keys)
+		assert.Equal([]string{"db.max_conns", "nmae"}, keys, name)
+
+		_, err = test.dec([]byte(test.invalid), &conf{})
+		assert.Equal("db.max-conns", fileError("", err).Key, name)
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{"prod.env": "NAME=prod\nPORT=80\n"})
+	defer os.RemoveAll(dir)
+
+	conf := testConfig{}
+	assert.NoError(Load(dir, "prod", &conf))
+	assert.Equal(testConfig{}, conf)
+
+	RegisterFormat(".env", func(data []byte, conf interface{}) ([]string, error) {
+		c, ok := conf.(*testConfig)
+		if !ok {
+			return nil, errors.New("unexpected config type")
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if kv := strings.SplitN(line, "=", 2); kv[0] == "NAME" {
+				c.Name = kv[1]
+			}
+		}
+		return nil, nil
+	})
+	defer func() {
+		formatMutex.Lock()
+		delete(formats, "env")
+		formatMutex.Unlock()
+	}()
+	assert.Contains(Formats(), "env")
+	assert.NoError(Load(dir, "prod", &conf))
+	assert.Equal(testConfig{Name: "prod"}, conf)
+}
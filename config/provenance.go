@@ -0,0 +1,224 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Layers reported for values that are not set by a config file
+const (
+	// LayerDefault marks values set by a default tag
+	LayerDefault = "default"
+	// LayerNone marks values no layer has set
+	LayerNone = "-"
+)
+
+// Entry describes an effective key of a config, it's value and the layer that supplied it. Layer is
+// the config file, e.g. prod.toml, "source " or "env " followed by the source or variable name,
+// LayerDefault or LayerNone.
+// Secret values are replaced by Mask.
+type Entry struct {
+	Key    string
+	Value  string
+	Layer  string
+	Secret bool
+}
+
+// Report lists the effective keys of a config
+type Report []Entry
+
+// String formats the report as aligned columns of key, value and layer, one key per line
+func (r Report) String() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	for _, e := range r {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", e.Key, e.Value, e.Layer)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// Provenance loads the config for env from root into conf like Load and reports every effective key
+// of conf and the layer that supplied it's value. Keys are listed in the order of the fields of conf.
+//
+// With a nil conf only the files, Sources and environment variables are considered, keys are those found in
+// the files and listed in sorted order. Env tags and defaults are unknown without the struct so a
+// config that depends on them is not fully reported.
+//
+// Decoders of all formats used must support decoding into a map[string]interface{}.
+func Provenance(root, env string, conf interface{}) (Report, error) {
+	tr := newTrace()
+	if conf == nil {
+		return tr.generic(root, env)
+	}
+	if err := load(root, env, conf, tr); err != nil {
+		return nil, err
+	}
+	r := Report{}
+	err := walkStruct(conf, func(field string, f reflect.StructField, v reflect.Value) error {
+		if isTable(v.Type()) {
+			return nil
+		}
+		e := Entry{Key: field, Value: leafValue(v), Layer: tr.layer(field)}
+		if tr.secrets[field] || f.Tag.Get("secret") == "true" {
+			e.Value, e.Secret = Mask, true
+		}
+		r = append(r, e)
+		return nil
+	})
+	return r, err
+}
+
+// leafValue formats the value of a field for a report. Pointers are dereferenced and nil pointers
+// reported as an empty value.
+func leafValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// trace records the layer that last set each key during a load
+type trace struct {
+	seq     int
+	layers  map[string]traced
+	values  map[string]interface{}
+	secrets map[string]bool
+}
+
+// traced is the layer that set a key and the order in which it did so
+type traced struct {
+	key   string
+	layer string
+	seq   int
+}
+
+// newTrace creates a new, empty trace
+func newTrace() *trace {
+	return &trace{
+		layers:  map[string]traced{},
+		values:  map[string]interface{}{},
+		secrets: map[string]bool{},
+	}
+}
+
+// set records that the layer set the key. Keys are matched case insensitively like toml does.
+func (t *trace) set(key, layer string) {
+	if t == nil {
+		return
+	}
+	t.seq++
+	t.layers[strings.ToLower(key)] = traced{key: key, layer: layer, seq: t.seq}
+}
+
+// secret records keys holding secrets
+func (t *trace) secret(keys ...string) {
+	if t == nil {
+		return
+	}
+	for _, k := range keys {
+		t.secrets[k] = true
+	}
+}
+
+// file records the keys set by a config file
+func (t *trace) file(file string, dec Decoder, data []byte) error {
+	if t == nil {
+		return nil
+	}
+	m := map[string]interface{}{}
+	if _, err := dec(data, &m); err != nil {
+		return err
+	}
+	flatten("", m, func(key string, v interface{}) {
+		t.set(key, path.Base(file))
+		t.values[strings.ToLower(key)] = v
+	})
+	return nil
+}
+
+// layer returns the layer that last set the key or any key within it, such as the entries of a map
+func (t *trace) layer(key string) string {
+	key = strings.ToLower(key)
+	last := traced{layer: LayerNone}
+	for k, tr := range t.layers {
+		if (k == key || strings.HasPrefix(k, key+".")) && tr.seq > last.seq {
+			last = tr
+		}
+	}
+	return last.layer
+}
+
+// generic reports the keys found in the files of the env chain and their environment overrides
+func (t *trace) generic(root, env string) (Report, error) {
+	chain, err := envChain(env)
+	if err != nil {
+		return nil, err
+	}
+	var errl ErrorList
+	for _, e := range chain {
+		errl = errl.append(loadEnv(root, e, nil, t))
+	}
+	for _, s := range Sources {
+		values, err := s.Values()
+		if err != nil {
+			errl = append(errl, SourceError{Source: s.Name(), Err: err})
+			continue
+		}
+		for k, v := range values {
+			t.set(k, "source "+s.Name())
+			t.values[strings.ToLower(k)] = v
+		}
+	}
+	keys := []string{}
+	for k := range t.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	r := Report{}
+	for _, k := range keys {
+		tr := t.layers[k]
+		e := Entry{Key: tr.key, Value: fmt.Sprintf("%v", t.values[k]), Layer: tr.layer}
+		if EnvPrefix != "" {
+			name := joinEnv(envName(EnvPrefix), envName(tr.key))
+			if v, ok := os.LookupEnv(name); ok {
+				e.Value, e.Layer = v, "env "+name
+			}
+		}
+		if res, ref, ok := resolver(e.Value); ok {
+			if _, err := res(ref); err != ErrNotSecret {
+				if err != nil {
+					errl = append(errl, SecretError{Field: e.Key, Ref: e.Value, Err: err})
+				}
+				e.Value, e.Secret = Mask, true
+			}
+		}
+		r = append(r, e)
+	}
+	return r, errorList(errl)
+}
+
+// flatten calls fn for every value of the nested map m with the dotted path of it's key
+func flatten(prefix string, m interface{}, fn func(key string, v interface{})) {
+	switch t := m.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			flatten(joinKey(prefix, k), v, fn)
+		}
+	case map[interface{}]interface{}:
+		for k, v := range t {
+			flatten(joinKey(prefix, fmt.Sprint(k)), v, fn)
+		}
+	default:
+		fn(prefix, m)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type provConfig struct {
+	Name   string
+	Port   int    `default:"8080"`
+	Level  string `default:"info"`
+	Pass   string
+	Token  string `secret:"true"`
+	Labels map[string]string
+	Conns  *int
+	Limit  *int
+	DB     struct {
+		Host string `toml:"host"`
+		User string `toml:"user"`
+	} `toml:"db"`
+}
+
+func TestProvenance(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"prod.toml": "name = \"prod\"\npass = \"env:TEST_PASS\"\nconns = 5\n[db]\nhost = \"prod-db\"\nuser = \"app\"\n",
+		"test.yaml": "level: warn\nlabels:\n  team: core\n",
+		"dev.json":  `{"db": {"host": "localhost"}}`,
+	})
+	defer os.RemoveAll(dir)
+	defer setenv(map[string]string{"TEST_PASS": "p4ss", "APP_DB_USER": "dev"})()
+	EnvPrefix = "APP"
+	defer func() { EnvPrefix = "" }()
+
+	r, err := Provenance(dir, "dev", &provConfig{Token: "t0ken"})
+	assert.NoError(err)
+	assert.Equal(Report{
+		{Key: "Name", Value: "prod", Layer: "prod.toml"},
+		{Key: "Port", Value: "8080", Layer: LayerDefault},
+		{Key: "Level", Value: "warn", Layer: "test.yaml"},
+		{Key: "Pass", Value: Mask, Layer: "prod.toml", Secret: true},
+		{Key: "Token", Value: Mask, Layer: LayerNone, Secret: true},
+		{Key: "Labels", Value: "map[team:core]", Layer: "test.yaml"},
+		{Key: "Conns", Value: "5", Layer: "prod.toml"},
+		{Key: "Limit", Value: "", Layer: LayerNone},
+		{Key: "db.host", Value: "localhost", Layer: "dev.json"},
+		{Key: "db.user", Value: "dev", Layer: "env APP_DB_USER"},
+	}, r)
+	assert.Contains(r.String(), "db.host  localhost       dev.json\n")
+
+	_, err = Provenance(dir, "staging", &provConfig{})
+	assert.Equal(UnknownEnvError{Env: "staging"}, err)
+}
+
+func TestProvenanceGeneric(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"prod.toml": "name = \"prod\"\npass = \"env:TEST_PASS\"\n[db]\nhost = \"prod-db\"\nuser = \"app\"\n",
+		"test.yaml": "level: warn\nlabels:\n  team: core\n",
+		"dev.json":  `{"db": {"host": "localhost"}}`,
+	})
+	defer os.RemoveAll(dir)
+	defer setenv(map[string]string{"TEST_PASS": "p4ss", "APP_DB_USER": "dev"})()
+	EnvPrefix = "APP"
+	defer func() { EnvPrefix = "" }()
+
+	r, err := Provenance(dir, "dev", nil)
+	assert.NoError(err)
+	assert.Equal(Report{
+		{Key: "db.host", Value: "localhost", Layer: "dev.json"},
+		{Key: "db.user", Value: "dev", Layer: "env APP_DB_USER"},
+		{Key: "labels.team", Value: "core", Layer: "test.yaml"},
+		{Key: "level", Value: "warn", Layer: "test.yaml"},
+		{Key: "name", Value: "prod", Layer: "prod.toml"},
+		{Key: "pass", Value: Mask, Layer: "prod.toml", Secret: true},
+	}, r)
+
+	os.Unsetenv("TEST_PASS")
+	r, err = Provenance(dir, "prod", nil)
+	assert.IsType(ErrorList{}, err)
+	assert.Len(r, 4)
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Mask replaces the value of secrets in logs and reports
+const Mask = "******"
+
+// Resolver returns the secret a reference points to. The reference is passed without it's scheme,
+// for file:///run/secrets/db_pass the file resolver receives ///run/secrets/db_pass. A Resolver
+// returns ErrNotSecret for values that only share it's scheme, such as the file:app.db DSN of
+// SQLite, so they are left alone.
+type Resolver func(ref string) (string, error)
+
+// ErrNotSecret is returned by a Resolver for a value that is not a reference to a secret
+var ErrNotSecret = errors.New("not a secret reference")
+
+var (
+	resolverMutex = sync.RWMutex{}
+	resolvers     = map[string]Resolver{
+		"file": ResolveFile,
+		"env":  ResolveEnv,
+	}
+)
+
+// SecretError describes a secret reference that cannot be resolved
+type SecretError struct {
+	Field string
+	Ref   string
+	Err   error
+}
+
+// Error implements error interface
+func (e SecretError) Error() string {
+	return fmt.Sprintf("config: %s: cannot resolve %s: %s", e.Field, e.Ref, e.Err)
+}
+
+// RegisterResolver registers a resolver for secret references with the given scheme, replacing any
+// resolver registered for it before. file and env are registered by default.
+func RegisterResolver(scheme string, r Resolver) {
+	resolverMutex.Lock()
+	resolvers[scheme] = r
+	resolverMutex.Unlock()
+}
+
+// resolver returns the resolver of the scheme of a reference such as env:DB_PASS
+func resolver(ref string) (Resolver, string, bool) {
+	i := strings.Index(ref, ":")
+	if i < 1 {
+		return nil, "", false
+	}
+	resolverMutex.RLock()
+	defer resolverMutex.RUnlock()
+
+	r, ok := resolvers[ref[:i]]
+	return r, ref[i+1:], ok
+}
+
+// ResolveFile resolves file://path references to the content of the file with trailing new lines
+// removed. Values without the // such as file:app.db are not references.
+func ResolveFile(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "//") {
+		return "", ErrNotSecret
+	}
+	b, err := ioutil.ReadFile(strings.TrimPrefix(ref, "//"))
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(b, "\r\n")), nil
+}
+
+// ResolveEnv resolves env:NAME references to the value of the environment variable
+func ResolveEnv(ref string) (string, error) {
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return v, nil
+}
+
+// ResolveSecrets replaces string values of conf, which must be a pointer to a struct, that are
+// references to a secret such as file:///run/secrets/db_pass or env:DB_PASS with the secret.
+// Strings and slices of strings are resolved, values whose scheme has no registered Resolver or
+// that the Resolver reports as ErrNotSecret are left alone. The keys of the fields holding secrets are returned so they can be passed to Redact.
+func ResolveSecrets(conf interface{}) ([]string, error) {
+	secrets := []string{}
+	err := walkStruct(conf, func(field string, f reflect.StructField, v reflect.Value) error {
+		var errl ErrorList
+		resolve := func(s reflect.Value) {
+			r, ref, ok := resolver(s.String())
+			if !ok {
+				return
+			}
+			secret, err := r(ref)
+			if err == ErrNotSecret {
+				return
+			}
+			if err != nil {
+				errl = append(errl, SecretError{Field: field, Ref: s.String(), Err: err})
+				return
+			}
+			s.SetString(secret)
+			if len(secrets) == 0 || secrets[len(secrets)-1] != field {
+				secrets = append(secrets, field)
+			}
+		}
+		switch {
+		case v.Kind() == reflect.String:
+			resolve(v)
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+			for i := 0; i < v.Len(); i++ {
+				resolve(v.Index(i))
+			}
+		}
+		return errorList(errl)
+	})
+	return secrets, err
+}
+
+// Redact formats conf like fmt's %+v verb with the value of the given fields and of fields tagged
+// secret:"true" replaced by Mask
+func Redact(conf interface{}, secrets []string) string {
+	v := reflect.ValueOf(conf)
+	masked := map[string]bool{}
+	for _, s := range secrets {
+		masked[s] = true
+	}
+	buf := &bytes.Buffer{}
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		buf.WriteString("&")
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Sprintf("%+v", conf)
+	}
+	redact(buf, "", v, masked)
+	return buf.String()
+}
+
+// redact writes struct s to buf with masked fields replaced
+func redact(buf *bytes.Buffer, prefix string, s reflect.Value, masked map[string]bool) {
+	t := s.Type()
+	buf.WriteString("{")
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(f.Name + ":")
+		field := joinKey(prefix, tomlKey(f))
+		v := s.Field(i)
+		switch {
+		case f.PkgPath != "":
+			buf.WriteString("?")
+		case masked[field] || f.Tag.Get("secret") == "true":
+			buf.WriteString(Mask)
+		case isTable(v.Type()) && v.Kind() == reflect.Ptr && !v.IsNil():
+			buf.WriteString("&")
+			redact(buf, field, v.Elem(), masked)
+		case isTable(v.Type()) && v.Kind() == reflect.Struct:
+			redact(buf, field, v, masked)
+		default:
+			fmt.Fprintf(buf, "%+v", v.Interface())
+		}
+	}
+	buf.WriteString("}")
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/codeblanche/golibs/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+type secretConfig struct {
+	User   string
+	Pass   string
+	Tokens []string
+	APIKey string `secret:"true"`
+	DB     struct {
+		Host string `toml:"host"`
+		Pass string `toml:"pass"`
+	} `toml:"db"`
+}
+
+func TestResolveSecrets(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{"db_pass": "s3cret\n"})
+	defer os.RemoveAll(dir)
+	defer setenv(map[string]string{"TEST_PASS": "p4ss", "TEST_TOKEN": "t0ken"})()
+
+	conf := secretConfig{
+		User:   "localhost:8080",
+		Pass:   "env:TEST_PASS",
+		Tokens: []string{"plain", "env:TEST_TOKEN", "file:probe.db?cache=shared"},
+		APIKey: "file:" + path.Join(dir, "db_pass"),
+	}
+	conf.DB.Host = "http://db"
+	conf.DB.Pass = "file://" + path.Join(dir, "db_pass")
+	secrets, err := ResolveSecrets(&conf)
+	assert.NoError(err)
+	assert.Equal([]string{"Pass", "Tokens", "db.pass"}, secrets)
+	assert.Equal("localhost:8080", conf.User)
+	assert.Equal("p4ss", conf.Pass)
+	assert.Equal([]string{"plain", "t0ken", "file:probe.db?cache=shared"}, conf.Tokens)
+	assert.Equal("file:"+path.Join(dir, "db_pass"), conf.APIKey, "Only file:// values are file references")
+	assert.Equal("http://db", conf.DB.Host)
+	assert.Equal("s3cret", conf.DB.Pass)
+
+	conf = secretConfig{Pass: "env:TEST_MISSING", User: "file:///does/not/exist"}
+	_, err = ResolveSecrets(&conf)
+	if assert.IsType(ErrorList{}, err) && assert.Len(err, 2) {
+		assert.Equal("User", err.(ErrorList)[0].(SecretError).Field)
+		assert.Equal("env:TEST_MISSING", err.(ErrorList)[1].(SecretError).Ref)
+	}
+}
+
+func TestRegisterResolver(t *testing.T) {
+	assert := assert.New(t)
+	RegisterResolver("vault", func(ref string) (string, error) {
+		if ref == "secret/db" {
+			return "v4ult", nil
+		}
+		return "", errors.New("not found")
+	})
+	defer func() {
+		resolverMutex.Lock()
+		delete(resolvers, "vault")
+		resolverMutex.Unlock()
+	}()
+
+	conf := secretConfig{Pass: "vault:secret/db"}
+	_, err := ResolveSecrets(&conf)
+	assert.NoError(err)
+	assert.Equal("v4ult", conf.Pass)
+}
+
+func TestRedact(t *testing.T) {
+	assert := assert.New(t)
+	conf := secretConfig{User: "admin", Pass: "p4ss", Tokens: []string{"a"}, APIKey: "k3y"}
+	conf.DB.Host, conf.DB.Pass = "db", "s3cret"
+
+	assert.Equal("&{User:admin Pass:****** Tokens:[a] APIKey:****** DB:{Host:db Pass:******}}", Redact(&conf, []string{"Pass", "db.pass"}))
+	assert.Equal("{User:admin Pass:p4ss Tokens:[a] APIKey:****** DB:{Host:db Pass:s3cret}}", Redact(conf, nil))
+	assert.Equal("42", Redact(42, nil))
+}
+
+func TestLoadSecrets(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{"prod.toml": "user = \"admin\"\npass = \"env:TEST_PASS\"\n"})
+	defer os.RemoveAll(dir)
+	defer setenv(map[string]string{"TEST_PASS": "p4ss"})()
+
+	logged := []string{}
+	debugf = func(msg string, v ...interface{}) string {
+		logged = append(logged, fmt.Sprintf(msg, v...))
+		return ""
+	}
+	defer func() { debugf = logr.Debugf }()
+	conf := secretConfig{}
+	assert.NoError(Load(dir, "prod", &conf))
+	assert.Equal("p4ss", conf.Pass)
+	if assert.Len(logged, 1) {
+		assert.Contains(logged[0], "Pass:******")
+		assert.NotContains(logged[0], "p4ss")
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	// Sources are applied by Load in order after the env files, e.g. to read config from a key-value
+	// store in some environments
+	Sources = []Source{}
+)
+
+// Source supplies config values from outside the env files, such as a Consul or etcd key-value
+// store. Keys are dotted paths matching the keys of the toml files, e.g. db.host for host in table
+// [db]. Values are converted to the type of their field as described on ApplyEnv.
+type Source interface {
+	// Name identifies the source in errors and provenance reports
+	Name() string
+	// Values returns the current keys and values of the source
+	Values() (map[string]string, error)
+}
+
+// SourceError describes a source that cannot be read or a value of a source that cannot be
+// converted to the type of it's field
+type SourceError struct {
+	Source string
+	Key    string
+	Err    error
+}
+
+// Error implements error interface
+func (e SourceError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("config: source %s: %s", e.Source, e.Err)
+	}
+	return fmt.Sprintf("config: source %s key %q: %s", e.Source, e.Key, e.Err)
+}
+
+// DirSource reads one key per file from a directory, like Kubernetes ConfigMap and Secret mounts.
+// The file name is the key and it's content, with trailing new lines removed, the value. Files in
+// sub directories are keys of tables, db/host is the key db.host. Hidden files and directories,
+// such as the ..data links of a mount, are skipped.
+//
+// DirSource also serves as a local stand-in for a key-value store in tests and development.
+type DirSource struct {
+	Dir string
+}
+
+// NewDirSource creates a new DirSource reading from the given directory
+func NewDirSource(dir string) DirSource {
+	return DirSource{Dir: dir}
+}
+
+// Name implements Source interface
+func (s DirSource) Name() string {
+	return s.Dir
+}
+
+// Values implements Source interface
+func (s DirSource) Values() (map[string]string, error) {
+	values := map[string]string{}
+	return values, readDir(s.Dir, "", values)
+}
+
+// readDir reads the files of dir into values, following links as mounts link keys to their current
+// version
+func readDir(dir, prefix string, values map[string]string) error {
+	list, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, info := range list {
+		if strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(dir, info.Name())
+		if info, err = os.Stat(p); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if err := readDir(p, joinKey(prefix, info.Name()), values); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		values[joinKey(prefix, info.Name())] = string(bytes.TrimRight(b, "\r\n"))
+	}
+	return nil
+}
+
+// applySource sets the fields of conf matching the keys of the source. Keys are matched case
+// insensitively like toml does, keys that match no field are reported as an UndecodedError.
+func applySource(s Source, conf interface{}, tr *trace) error {
+	values, err := s.Values()
+	if err != nil {
+		return SourceError{Source: s.Name(), Err: err}
+	}
+	keys := map[string]string{}
+	for k := range values {
+		keys[strings.ToLower(k)] = k
+	}
+	errl := ErrorList{}.append(walkStruct(conf, func(field string, f reflect.StructField, v reflect.Value) error {
+		k, ok := keys[strings.ToLower(field)]
+		if !ok || isTable(v.Type()) {
+			return nil
+		}
+		delete(keys, strings.ToLower(field))
+		if err := setValue(v, values[k]); err != nil {
+			return SourceError{Source: s.Name(), Key: k, Err: err}
+		}
+		tr.set(field, "source "+s.Name())
+		return nil
+	}))
+	if len(keys) > 0 {
+		undecoded := []string{}
+		for _, k := range keys {
+			undecoded = append(undecoded, k)
+		}
+		sort.Strings(undecoded)
+		errl = append(errl, UndecodedError{File: s.Name(), Keys: undecoded})
+	}
+	return errorList(errl)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sourceConfig struct {
+	Name  string
+	Port  int
+	Hosts []string
+	DB    struct {
+		Host string `toml:"host"`
+		Pass string `toml:"pass"`
+	} `toml:"db"`
+}
+
+// writeDirSource creates a mounted style directory source with the given keys
+func writeDirSource(t *testing.T, values map[string]string) string {
+	dir := writeConfigs(t, nil)
+	data := path.Join(dir, "..2019_01_01")
+	if err := os.Symlink("..2019_01_01", path.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range values {
+		p := path.Join(data, k)
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(v), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// mounts link the top level entries to the current version
+		top := strings.Split(k, "/")[0]
+		if err := os.Symlink(path.Join("..data", top), path.Join(dir, top)); err != nil && !os.IsExist(err) {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDirSource(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeDirSource(t, map[string]string{
+		"name":    "kv\n",
+		"db/host": "kv-db",
+	})
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(path.Join(dir, ".hidden"), []byte("x"), 0644)
+
+	s := NewDirSource(dir)
+	assert.Equal(dir, s.Name())
+	values, err := s.Values()
+	assert.NoError(err)
+	assert.Equal(map[string]string{"name": "kv", "db.host": "kv-db"}, values)
+
+	_, err = NewDirSource(path.Join(dir, "missing")).Values()
+	assert.Error(err)
+}
+
+func TestLoadSources(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{"prod.toml": "name = \"prod\"\nport = 80\n[db]\nhost = \"db\"\n"})
+	defer os.RemoveAll(dir)
+	kv := writeDirSource(t, map[string]string{
+		"port":    "8080",
+		"hosts":   "a,b",
+		"db/host": "kv-db",
+		"db/pass": "env:TEST_PASS",
+	})
+	defer os.RemoveAll(kv)
+	defer setenv(map[string]string{"TEST_PASS": "p4ss", "APP_PORT": "9090"})()
+
+	Sources = []Source{NewDirSource(kv)}
+	defer func() { Sources = []Source{} }()
+	conf := sourceConfig{}
+	assert.NoError(Load(dir, "prod", &conf))
+	assert.Equal("prod", conf.Name)
+	assert.Equal(8080, conf.Port)
+	assert.Equal([]string{"a", "b"}, conf.Hosts)
+	assert.Equal("kv-db", conf.DB.Host)
+	assert.Equal("p4ss", conf.DB.Pass)
+
+	EnvPrefix = "APP"
+	defer func() { EnvPrefix = "" }()
+	r, err := Provenance(dir, "prod", &sourceConfig{})
+	assert.NoError(err)
+	assert.Equal(Report{
+		{Key: "Name", Value: "prod", Layer: "prod.toml"},
+		{Key: "Port", Value: "9090", Layer: "env APP_PORT"},
+		{Key: "Hosts", Value: "[a b]", Layer: "source " + kv},
+		{Key: "db.host", Value: "kv-db", Layer: "source " + kv},
+		{Key: "db.pass", Value: Mask, Layer: "source " + kv, Secret: true},
+	}, r)
+
+	r, err = Provenance(dir, "prod", nil)
+	assert.NoError(err)
+	assert.Len(r, 5)
+}
+
+func TestLoadSourceErrors(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, nil)
+	defer os.RemoveAll(dir)
+	kv := writeDirSource(t, map[string]string{
+		"port":    "http",
+		"nmae":    "kv",
+		"db/user": "app",
+	})
+	defer os.RemoveAll(kv)
+
+	Sources = []Source{NewDirSource(kv), NewDirSource(path.Join(kv, "missing"))}
+	defer func() { Sources = []Source{} }()
+	err := Load(dir, "prod", &sourceConfig{})
+	if !assert.IsType(ErrorList{}, err) || !assert.Len(err, 3) {
+		return
+	}
+	errl := err.(ErrorList)
+	assert.Equal("port", errl[0].(SourceError).Key)
+	assert.Equal(UndecodedError{File: kv, Keys: []string{"db.user", "nmae"}}, errl[1])
+	assert.Equal(path.Join(kv, "missing"), errl[2].(SourceError).Source)
+	assert.Equal("", errl[2].(SourceError).Key)
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codeblanche/golibs/slice"
+)
+
+// FieldError describes a config field that fails the rule of one of it's tags
+type FieldError struct {
+	Field string
+	Tag   string
+	Err   error
+}
+
+// Error implements error interface
+func (e FieldError) Error() string {
+	return fmt.Sprintf("config: %s: %s", e.Field, e.Err)
+}
+
+// ApplyDefaults sets the fields of conf, which must be a pointer to a struct, that are left at their
+// zero value to the value of their default tag, converted as described on ApplyEnv. Nested structs
+// are included, nil pointers to structs are not. Load applies defaults before any file so explicit
+// zero values are kept. For that Load allocates nil pointer tables with defaults first and sets them
+// back to nil when no layer changed them.
+func ApplyDefaults(conf interface{}) error {
+	return applyDefaults(conf, nil)
+}
+
+// applyDefaults sets the default values and records the fields set in the trace
+func applyDefaults(conf interface{}, tr *trace) error {
+	return walkStruct(conf, func(field string, f reflect.StructField, v reflect.Value) error {
+		def, ok := f.Tag.Lookup("default")
+		if !ok || !isZero(v) {
+			return nil
+		}
+		if err := setValue(v, def); err != nil {
+			return FieldError{Field: field, Tag: "default", Err: err}
+		}
+		tr.set(field, LayerDefault)
+		return nil
+	})
+}
+
+// defaultTable is a table behind a nil pointer that was allocated with it's defaults before loading
+type defaultTable struct {
+	field    reflect.Value
+	defaults reflect.Value
+}
+
+// allocDefaultTables allocates the nil pointer tables of conf that have default tags, so their
+// defaults are applied before any layer like those of other fields. It returns the tables so the
+// untouched ones can be released again with releaseDefaultTables.
+func allocDefaultTables(conf interface{}) []defaultTable {
+	return allocTables(conf, map[reflect.Type]bool{reflect.TypeOf(conf).Elem(): true})
+}
+
+// allocTables implements allocDefaultTables. Types in parents are not allocated again so recursive
+// types end.
+func allocTables(conf interface{}, parents map[reflect.Type]bool) []defaultTable {
+	tables := []defaultTable{}
+	s := reflect.ValueOf(conf).Elem()
+	for i := 0; i < s.NumField(); i++ {
+		f, v := s.Type().Field(i), s.Field(i)
+		if f.PkgPath != "" || !isTable(v.Type()) {
+			continue
+		}
+		if v.Kind() != reflect.Ptr {
+			tables = append(tables, allocTables(v.Addr().Interface(), parents)...)
+			continue
+		}
+		if !v.IsNil() {
+			tables = append(tables, allocTables(v.Interface(), parents)...)
+			continue
+		}
+		t := v.Type().Elem()
+		if parents[t] || !hasDefaults(t, map[reflect.Type]bool{}) {
+			continue
+		}
+		table, nested := newDefaultTable(t, parents)
+		defaults, _ := newDefaultTable(t, parents)
+		v.Set(table)
+		tables = append(append(tables, defaultTable{field: v, defaults: defaults}), nested...)
+	}
+	return tables
+}
+
+// releaseDefaultTables sets the tables no layer changed back to nil, so tables only holding their
+// defaults are absent as before. A table set to exactly it's defaults by a layer is released too.
+// Tables are listed before the tables nested in them.
+func releaseDefaultTables(tables []defaultTable) {
+	for _, t := range tables {
+		if !t.field.IsNil() && reflect.DeepEqual(t.field.Interface(), t.defaults.Interface()) {
+			t.field.Set(reflect.Zero(t.field.Type()))
+		}
+	}
+}
+
+// newDefaultTable creates a new table of type t with it's defaults applied and returns it with the
+// nested pointer tables allocated for it
+func newDefaultTable(t reflect.Type, parents map[reflect.Type]bool) (reflect.Value, []defaultTable) {
+	v := reflect.New(t)
+	parents[t] = true
+	nested := allocTables(v.Interface(), parents)
+	delete(parents, t)
+	applyDefaults(v.Interface(), nil)
+	return v, nested
+}
+
+// hasDefaults checks if any field of the struct type or of it's nested tables has a default tag
+func hasDefaults(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if _, ok := f.Tag.Lookup("default"); ok {
+			return true
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if isTable(f.Type) && hasDefaults(ft, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the fields of conf, which must be a pointer to a struct, against the rules of their
+// tags and returns every failure as a FieldError in an ErrorList. Nested structs are included.
+//
+//	required:"true"   the field must not be it's zero value
+//	min:"1" max:"10"  bounds for numbers and durations or for the length of strings, slices and maps
+//	oneof:"a b c"     the field must be one of the space separated values
+//
+// The min, max and oneof rules apply to zero values too, so min:"1" rejects 0 and oneof rejects an
+// empty string. Give optional fields a default instead. Nil pointers are not checked.
+func Validate(conf interface{}) error {
+	return walkStruct(conf, func(field string, f reflect.StructField, v reflect.Value) error {
+		var errl ErrorList
+		if f.Tag.Get("required") == "true" && isZero(v) {
+			errl = append(errl, FieldError{Field: field, Tag: "required", Err: fmt.Errorf("is required")})
+		}
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return errorList(errl)
+		}
+		for _, tag := range []string{"min", "max"} {
+			bound, ok := f.Tag.Lookup(tag)
+			if !ok {
+				continue
+			}
+			if err := checkBound(v, tag, bound); err != nil {
+				errl = append(errl, FieldError{Field: field, Tag: tag, Err: err})
+			}
+		}
+		if oneof, ok := f.Tag.Lookup("oneof"); ok {
+			s := fmt.Sprint(reflect.Indirect(v).Interface())
+			if !slice.Strings(strings.Fields(oneof)).Contains(s) {
+				errl = append(errl, FieldError{Field: field, Tag: "oneof", Err: fmt.Errorf("%q is not one of %s", s, oneof)})
+			}
+		}
+		return errorList(errl)
+	})
+}
+
+// checkBound compares the value, or it's length, against a min or max bound
+func checkBound(v reflect.Value, tag, bound string) error {
+	v = reflect.Indirect(v)
+	var n, b float64
+	var err error
+	what := "value"
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		var d time.Duration
+		d, err = time.ParseDuration(bound)
+		n, b = float64(v.Int()), float64(d)
+	case v.Kind() == reflect.String || v.Kind() == reflect.Slice || v.Kind() == reflect.Map:
+		n, what = float64(v.Len()), "length"
+		b, err = strconv.ParseFloat(bound, 64)
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		n = float64(v.Int())
+		b, err = strconv.ParseFloat(bound, 64)
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64:
+		n = float64(v.Uint())
+		b, err = strconv.ParseFloat(bound, 64)
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		n = v.Float()
+		b, err = strconv.ParseFloat(bound, 64)
+	default:
+		return fmt.Errorf("%s is not supported for type %s", tag, v.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %s", tag, bound, err)
+	}
+	if tag == "min" && n < b {
+		return fmt.Errorf("%s must be at least %s", what, bound)
+	}
+	if tag == "max" && n > b {
+		return fmt.Errorf("%s must be at most %s", what, bound)
+	}
+	return nil
+}
+
+// walkStruct calls fn for every exported field of the struct conf points to, recursing into nested
+// structs and non nil pointers to structs, and collects the errors returned
+func walkStruct(conf interface{}, fn func(field string, f reflect.StructField, v reflect.Value) error) error {
+	v := reflect.ValueOf(conf)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: parameter 'conf' must be a pointer to a struct")
+	}
+	var errl ErrorList
+	walk("", v.Elem(), fn, &errl)
+	return errorList(errl)
+}
+
+// walk calls fn for the fields of struct s and recurses into nested structs
+func walk(prefix string, s reflect.Value, fn func(string, reflect.StructField, reflect.Value) error, errl *ErrorList) {
+	t := s.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		field := joinKey(prefix, tomlKey(f))
+		v := s.Field(i)
+		*errl = errl.append(fn(field, f, v))
+		if isTable(v.Type()) && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+			walk(field, reflect.Indirect(v), fn, errl)
+		}
+	}
+}
+
+// isZero checks if v is the zero value of it's type. Empty slices and maps count as zero.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validConfig struct {
+	Name    string        `required:"true"`
+	Enabled bool          `default:"true"`
+	Port    int           `default:"8080" min:"1" max:"65535"`
+	Level   string        `default:"info" oneof:"debug info warn error"`
+	Timeout time.Duration `default:"30s" min:"1s" max:"1m"`
+	Hosts   []string      `default:"localhost" max:"2"`
+	Ratio   float64       `max:"1"`
+	DB      struct {
+		Host  string `toml:"host" required:"true"`
+		Conns uint   `toml:"conns" min:"1"`
+	} `toml:"db"`
+	Cache *struct {
+		Size int `default:"64"`
+	}
+	Store *storeConfig `toml:"store"`
+}
+
+type storeConfig struct {
+	Host string `toml:"host"`
+	Port int    `toml:"port" default:"5432" min:"1"`
+	Next *storeConfig
+	Pool *struct {
+		Size int `toml:"size" default:"4"`
+	} `toml:"pool"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	assert := assert.New(t)
+	conf := validConfig{Port: 80}
+	assert.NoError(ApplyDefaults(&conf))
+	assert.Equal(80, conf.Port)
+	assert.Equal("info", conf.Level)
+	assert.Equal(30*time.Second, conf.Timeout)
+	assert.Equal([]string{"localhost"}, conf.Hosts)
+	assert.Nil(conf.Cache)
+
+	conf.Cache = &struct {
+		Size int `default:"64"`
+	}{}
+	assert.NoError(ApplyDefaults(&conf))
+	assert.Equal(64, conf.Cache.Size)
+
+	bad := struct {
+		Port int `default:"http"`
+	}{}
+	err := ApplyDefaults(&bad)
+	if assert.IsType(ErrorList{}, err) && assert.Len(err, 1) {
+		assert.Equal("Port", err.(ErrorList)[0].(FieldError).Field)
+		assert.Equal("default", err.(ErrorList)[0].(FieldError).Tag)
+	}
+	assert.Error(ApplyDefaults(bad))
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+	conf := validConfig{Name: "app", Port: 80, Level: "info", Timeout: time.Second}
+	conf.DB.Host, conf.DB.Conns = "db", 1
+	assert.NoError(Validate(&conf))
+
+	conf = validConfig{
+		Port:    70000,
+		Level:   "verbose",
+		Timeout: time.Millisecond,
+		Hosts:   []string{"a", "b", "c"},
+		Ratio:   1.5,
+	}
+	conf.DB.Conns = 0
+	err := Validate(&conf)
+	if !assert.IsType(ErrorList{}, err) {
+		return
+	}
+	failed := []string{}
+	for _, e := range err.(ErrorList) {
+		failed = append(failed, e.(FieldError).Field+":"+e.(FieldError).Tag)
+	}
+	assert.Equal([]string{
+		"Name:required",
+		"Port:max",
+		"Level:oneof",
+		"Timeout:min",
+		"Hosts:max",
+		"Ratio:max",
+		"db.host:required",
+		"db.conns:min",
+	}, failed)
+	assert.EqualError(err.(ErrorList)[1], "config: Port: value must be at most 65535")
+	assert.EqualError(err.(ErrorList)[4], "config: Hosts: length must be at most 2")
+
+	bad := struct {
+		Port int  `min:"one"`
+		On   bool `min:"1"`
+	}{Port: 1, On: true}
+	assert.Len(Validate(&bad), 2)
+}
+
+func TestLoadValidate(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"prod.toml": "name = \"prod\"\n[db]\nhost = \"db\"\nconns = 1\n",
+		"test.toml": "enabled = false\n[store]\nhost = \"h\"\n[cache]\nsize = 16\n",
+	})
+	defer os.RemoveAll(dir)
+
+	conf := validConfig{}
+	assert.NoError(Load(dir, "prod", &conf))
+	assert.Equal("prod", conf.Name)
+	assert.Equal(8080, conf.Port)
+	assert.Equal("info", conf.Level)
+	assert.True(conf.Enabled)
+
+	assert.Nil(conf.Cache, "tables no layer sets stay nil")
+	assert.Nil(conf.Store)
+
+	conf = validConfig{}
+	assert.NoError(Load(dir, "test", &conf))
+	assert.False(conf.Enabled, "explicit false overrides the default")
+	if assert.NotNil(conf.Store) {
+		assert.Equal(&storeConfig{Host: "h", Port: 5432}, conf.Store, "defaults apply to tables set by a layer")
+	}
+	assert.Equal(16, conf.Cache.Size)
+
+	dir2 := writeConfigs(t, map[string]string{"prod.toml": "port = 0\nlevel = \"trace\"\n"})
+	defer os.RemoveAll(dir2)
+	conf = validConfig{}
+	err := Load(dir2, "prod", &conf)
+	assert.Equal(0, conf.Port, "explicit 0 overrides the default")
+	if assert.IsType(ErrorList{}, err) && assert.Len(err, 5) {
+		assert.Equal("Port", err.(ErrorList)[1].(FieldError).Field)
+		assert.Equal("min", err.(ErrorList)[1].(FieldError).Tag)
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/codeblanche/golibs/fs/fsutils"
+	"github.com/codeblanche/golibs/logr"
+	"github.com/codeblanche/golibs/slice"
+)
+
+// Validator is implemented by configs that check their own values. Watched configs are validated
+// after every load and a config failing validation is never swapped in.
+type Validator interface {
+	Validate() error
+}
+
+// Watcher holds a config loaded from a root dir and reloads it when any of it's env files or the
+// directory of a DirSource change
+type Watcher struct {
+	root        string
+	env         string
+	typ         reflect.Type
+	value       atomic.Value
+	done        chan struct{}
+	closeOnce   sync.Once
+	reload      sync.Mutex
+	mutex       sync.Mutex
+	subscribers []func(old, new interface{})
+}
+
+// Watch loads the config for env from root into conf, which must be a pointer to a struct, and
+// keeps watching root for changes to the files of the env chain. Every change re-runs the cascade
+// into a new value of conf's type, validates it and atomically swaps it in before notifying the
+// subscribers. Invalid edits are logged and rejected, the previous config stays in place.
+//
+// The directories of DirSources in Sources when Watch is called are watched as well, so an updated
+// ConfigMap mount triggers a reload. Other sources are only read when a reload is triggered.
+//
+// conf itself is only populated by the initial load, use Config to get the current value.
+func Watch(root, env string, conf interface{}) (*Watcher, error) {
+	v := reflect.ValueOf(conf)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: parameter 'conf' must be a pointer to a struct")
+	}
+	if err := loadValid(root, env, conf); err != nil {
+		return nil, err
+	}
+	w := &Watcher{root: root, env: env, typ: v.Elem().Type(), done: make(chan struct{})}
+	w.value.Store(conf)
+	if err := fsutils.WatchUntil(root, w.done, w.changed); err != nil {
+		return nil, err
+	}
+	for _, s := range Sources {
+		var dir string
+		switch t := s.(type) {
+		case DirSource:
+			dir = t.Dir
+		case *DirSource:
+			dir = t.Dir
+		default:
+			continue
+		}
+		if err := fsutils.WatchUntil(dir, w.done, w.sourceChanged); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Config returns the current config as a pointer of the type given to Watch. The value must not be
+// modified as it is shared by every caller.
+func (w *Watcher) Config() interface{} {
+	return w.value.Load()
+}
+
+// Subscribe adds a func called with the old and new config after every reload that changed the
+// config. Subscribers are called in order of subscription, one reload at a time. They may call
+// Config and Subscribe but must not call Reload or Close.
+func (w *Watcher) Subscribe(fn func(old, new interface{})) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload re-runs the cascade and swaps the result in if it is valid. An invalid config is
+// returned as an error and the current config is kept.
+func (w *Watcher) Reload() error {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+
+	return w.load()
+}
+
+// Close stops watching the files and releases the underlying watcher, waiting for a reload
+// triggered by a change to finish. Config keeps returning the last value and Reload can still be
+// called.
+func (w *Watcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+	})
+	w.reload.Lock()
+	w.reload.Unlock()
+}
+
+// load runs the cascade and notifies the subscribers, the reload lock must be held
+func (w *Watcher) load() error {
+	conf := reflect.New(w.typ).Interface()
+	if err := loadValid(w.root, w.env, conf); err != nil {
+		return err
+	}
+	old := w.value.Load()
+	if reflect.DeepEqual(old, conf) {
+		return nil
+	}
+	w.value.Store(conf)
+	w.mutex.Lock()
+	subscribers := append([]func(old, new interface{}){}, w.subscribers...)
+	w.mutex.Unlock()
+	for _, fn := range subscribers {
+		fn(old, conf)
+	}
+	return nil
+}
+
+// changed reloads the config if the changed file is one of the env chain
+func (w *Watcher) changed(name string) {
+	w.reloadOn(name, w.watches)
+}
+
+// sourceChanged reloads the config on any change in the directory of a DirSource
+func (w *Watcher) sourceChanged(name string) {
+	w.reloadOn(name, func(string) bool { return true })
+}
+
+// reloadOn reloads the config for a change to the named file unless the watcher is closed or the
+// file is not relevant
+func (w *Watcher) reloadOn(name string, relevant func(name string) bool) {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+
+	if w.closed() || !relevant(name) {
+		return
+	}
+	logr.Infof("Config file %s changed, reloading", name)
+	if err := w.load(); err != nil {
+		logr.Errorf("Rejected config change in %s: %s", name, err)
+	}
+}
+
+// closed checks if Close was called
+func (w *Watcher) closed() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// watches checks if the named file is a config file of the env chain
+func (w *Watcher) watches(name string) bool {
+	if path.Clean(path.Dir(name)) != path.Clean(w.root) {
+		return false
+	}
+	ext := path.Ext(name)
+	if !slice.Strings(Formats()).Contains(strings.TrimPrefix(ext, ".")) {
+		return false
+	}
+	chain, err := envChain(w.env)
+	if err != nil {
+		return false
+	}
+	return slice.Strings(chain).Contains(strings.TrimSuffix(path.Base(name), ext))
+}
+
+// loadValid loads conf with Load and validates the result
+func loadValid(root, env string, conf interface{}) error {
+	if err := Load(root, env, conf); err != nil {
+		return err
+	}
+	if v, ok := conf.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
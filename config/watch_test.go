@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type watchConfig struct {
+	Level string
+	Flags []string
+}
+
+func (c *watchConfig) Validate() error {
+	if c.Level == "" {
+		return errors.New("level is required")
+	}
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{
+		"prod.toml": "level = \"info\"\n",
+		"dev.toml":  "flags = [\"a\"]\n",
+	})
+	defer os.RemoveAll(dir)
+
+	conf := &watchConfig{}
+	w, err := Watch(dir, "test", conf)
+	if !assert.NoError(err) {
+		return
+	}
+	defer w.Close()
+	assert.Equal(&watchConfig{Level: "info"}, w.Config())
+
+	changes := make(chan [2]interface{}, 10)
+	w.Subscribe(func(old, new interface{}) {
+		changes <- [2]interface{}{old, new}
+	})
+
+	ioutil.WriteFile(path.Join(dir, "dev.toml"), []byte("level = \"none\"\n"), 0644)
+	ioutil.WriteFile(path.Join(dir, "other.toml"), []byte("level = \"none\"\n"), 0644)
+	ioutil.WriteFile(path.Join(dir, "test.toml"), []byte("level = \"debug\"\n"), 0644)
+	select {
+	case c := <-changes:
+		assert.Equal(&watchConfig{Level: "info"}, c[0])
+		assert.Equal(&watchConfig{Level: "debug"}, c[1])
+	case <-time.After(2 * time.Second):
+		assert.Fail("config was not reloaded")
+	}
+	assert.Equal(&watchConfig{Level: "debug"}, w.Config())
+	assert.Equal(&watchConfig{Level: "info"}, conf)
+}
+
+func TestWatchSources(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{"prod.toml": "level = \"info\"\n"})
+	defer os.RemoveAll(dir)
+	mount := writeDirSource(t, map[string]string{"flags": "a"})
+	defer os.RemoveAll(mount)
+	defer func() { Sources = []Source{} }()
+	Sources = []Source{NewDirSource(mount)}
+
+	w, err := Watch(dir, "prod", &watchConfig{})
+	if !assert.NoError(err) {
+		return
+	}
+	defer w.Close()
+	assert.Equal(&watchConfig{Level: "info", Flags: []string{"a"}}, w.Config())
+
+	changes := make(chan interface{}, 10)
+	w.Subscribe(func(old, new interface{}) { changes <- new })
+	ioutil.WriteFile(path.Join(mount, "..2019_01_01", "flags"), []byte("a,b"), 0644)
+	expected := &watchConfig{Level: "info", Flags: []string{"a", "b"}}
+	timeout := time.After(2 * time.Second)
+	// writing the file may trigger a reload of it's truncated content first
+	for c := interface{}(nil); !reflect.DeepEqual(expected, c); {
+		select {
+		case c = <-changes:
+		case <-timeout:
+			assert.Fail("config was not reloaded")
+			return
+		}
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{"prod.toml": "level = \"info\"\n"})
+	defer os.RemoveAll(dir)
+
+	w, err := Watch(dir, "prod", &watchConfig{})
+	if !assert.NoError(err) {
+		return
+	}
+	w.Close()
+	calls := 0
+	w.Subscribe(func(old, new interface{}) { calls++ })
+
+	assert.NoError(w.Reload())
+	assert.Equal(0, calls)
+
+	ioutil.WriteFile(path.Join(dir, "prod.toml"), []byte("level = 1\n"), 0644)
+	assert.IsType(ErrorList{}, w.Reload())
+	ioutil.WriteFile(path.Join(dir, "prod.toml"), []byte("flags = []\n"), 0644)
+	assert.EqualError(w.Reload(), "level is required")
+	assert.Equal(&watchConfig{Level: "info"}, w.Config())
+	assert.Equal(0, calls)
+
+	ioutil.WriteFile(path.Join(dir, "prod.toml"), []byte("level = \"warn\"\n"), 0644)
+	assert.NoError(w.Reload())
+	assert.Equal(&watchConfig{Level: "warn"}, w.Config())
+	assert.Equal(1, calls)
+
+	// subscribers may use the watcher without deadlocking
+	w.Subscribe(func(old, new interface{}) {
+		assert.Equal(new, w.Config())
+		w.Subscribe(func(old, new interface{}) { calls++ })
+	})
+	ioutil.WriteFile(path.Join(dir, "prod.toml"), []byte("level = \"error\"\n"), 0644)
+	assert.NoError(w.Reload())
+	assert.Equal(2, calls)
+	ioutil.WriteFile(path.Join(dir, "prod.toml"), []byte("level = \"info\"\n"), 0644)
+	assert.NoError(w.Reload())
+	assert.Equal(4, calls)
+}
+
+func TestWatchErrors(t *testing.T) {
+	assert := assert.New(t)
+	dir := writeConfigs(t, map[string]string{"prod.toml": "level = \"\"\n"})
+	defer os.RemoveAll(dir)
+
+	_, err := Watch(dir, "prod", &watchConfig{})
+	assert.EqualError(err, "level is required")
+	_, err = Watch(dir, "prod", watchConfig{})
+	assert.Error(err)
+	_, err = Watch(dir, "staging", &watchConfig{})
+	assert.Equal(UnknownEnvError{Env: "staging"}, err)
+
+	// the missing root loads without files but cannot be watched
+	w, err := Watch(path.Join(dir, "missing"), "prod", &testConfig{})
+	assert.True(os.IsNotExist(err), "unexpected error %v", err)
+	assert.Nil(w)
+}
@@ -69,6 +69,14 @@ func Index(m Model, key ...string) error {
 	return c(s, m).EnsureIndexKey(key...)
 }
 
+// UniqueIndex ensures a unique index on the given keys
+func UniqueIndex(m Model, key ...string) error {
+	s := session()
+	defer s.Close()
+
+	return c(s, m).EnsureIndex(mgo.Index{Key: key, Unique: true})
+}
+
 // Load a db model using it's ObjectID
 func Load(m Model) error {
 	return One(M{"_id": m.ObjectID()}, m)
@@ -130,6 +138,16 @@ func Update(m Model, query M) error {
 	return nil
 }
 
+// Upsert applies the update to the document of the model's collection matching the selector, or
+// inserts a new document when none matches, in a single atomic operation
+func Upsert(m Model, selector M, update interface{}) error {
+	s := session()
+	defer s.Close()
+
+	_, err := c(s, m).Upsert(selector, update)
+	return err
+}
+
 // Count counts numbers of row based on Query
 func Count(query M, into interface{}) (int, error) {
 	m, err := resolveModel(into)
@@ -11,26 +11,34 @@ import (
 
 // Watch for changes in the specified directory recursively
 func Watch(dir string, handler func(name string)) error {
+	return WatchUntil(dir, nil, handler)
+}
+
+// WatchUntil watches for changes in the specified directory recursively until done is closed. The
+// underlying watcher is released once done is closed. A nil done watches forever like Watch. The
+// directories are watched when WatchUntil returns, an error is returned if they cannot be.
+func WatchUntil(dir string, done <-chan struct{}, handler func(name string)) error {
 	watcher, err := fsnotify.NewWatcher()
 
 	if err != nil {
 		return err
 	}
 
-	go watch(watcher, dir, handler)
+	err = recursive(watcher, dir)
+
+	if err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go watch(watcher, done, handler)
 
 	return nil
 }
 
-func watch(watcher *fsnotify.Watcher, dir string, handler func(name string)) {
+func watch(watcher *fsnotify.Watcher, done <-chan struct{}, handler func(name string)) {
 	defer watcher.Close()
 
-	err := recursive(watcher, dir)
-
-	if err != nil {
-		return
-	}
-
 	for {
 		select {
 		case ev := <-watcher.Event:
@@ -41,6 +49,8 @@ func watch(watcher *fsnotify.Watcher, dir string, handler func(name string)) {
 			handler(ev.Name)
 		case <-watcher.Error:
 			// nothing to do for now
+		case <-done:
+			return
 		}
 	}
 }